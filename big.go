@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runBig handles `peek big [options] [path]`.
+func runBig(args []string) {
+	showAll := false
+	interactive := false
+	depth := 1
+
+	fl := newFlagSet("peek big [options] [path]")
+	fl.bool(&showAll, "a", "all", "count hidden files and dirs")
+	fl.bool(&interactive, "i", "interactive", "drill down with a cursor, like ncdu")
+	fl.value("d", "depth", "N", "rank dirs down to N levels (0 = all, default 1)", func(v string) { depth = parseDepth(v) })
+	target := onePath(fl.parse(args))
+
+	applyTheme()
+	scanner := &peek.Scanner{ShowAll: showAll, Progress: &peek.Progress{}}
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	defer loc.Close()
+
+	if interactive {
+		if err := runBigInteractive(loc); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	done := showProgress(scanner.Progress)
+	u, err := loc.scanner.Big(loc.dir, depth)
+	done()
+	if err != nil {
+		fatal(loc.fail(err))
+	}
+	r := peek.UsageRenderer{Width: termWidth(), Title: loc.title(absDir(loc, loc.dir))}
+	if err := r.Render(os.Stdout, u); err != nil {
+		fatal(err)
+	}
+}
+
+// Lines the drill-down view spends outside the dir rows:
+// path, box border and padding, header, footer, help.
+const bigChrome = 10
+
+type bigModel struct {
+	loc    *location
+	dir    string // OS path, or fs path when remote
+	usage  peek.Usage
+	cursor int
+	off    int
+	width  int
+	height int
+	err    error
+}
+
+func runBigInteractive(loc *location) error {
+	m := &bigModel{loc: loc, dir: loc.dir, width: termWidth()}
+	if !loc.remote() {
+		abs, err := filepath.Abs(loc.dir)
+		if err != nil {
+			return err
+		}
+		m.dir = abs
+	}
+	if err := m.load(""); err != nil {
+		return loc.fail(err)
+	}
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// load sizes the subdirs of m.dir and puts the cursor on focus, if any.
+func (m *bigModel) load(focus string) error {
+	u, err := m.loc.scanner.Big(m.dir, 1)
+	if err != nil {
+		return err
+	}
+	m.usage, m.cursor, m.off = u, 0, 0
+	for i, d := range u.Dirs {
+		if d.Name == focus {
+			m.cursor = i
+		}
+	}
+	m.scroll()
+	return nil
+}
+
+func (m *bigModel) join(name string) string {
+	if m.loc.remote() {
+		return path.Join(m.dir, name)
+	}
+	return filepath.Join(m.dir, name)
+}
+
+func (m *bigModel) parent() (dir, base string) {
+	if m.loc.remote() {
+		return path.Dir(m.dir), path.Base(m.dir)
+	}
+	return filepath.Dir(m.dir), filepath.Base(m.dir)
+}
+
+func (m *bigModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *bigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		m.err = nil
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = max(min(m.cursor+1, len(m.usage.Dirs)-1), 0)
+		case "enter", "right", "l":
+			if m.cursor < len(m.usage.Dirs) {
+				prev := m.dir
+				m.dir = m.join(m.usage.Dirs[m.cursor].Name)
+				if err := m.load(""); err != nil {
+					m.dir, m.err = prev, err
+				}
+			}
+		case "backspace", "left", "h":
+			if parent, base := m.parent(); parent != m.dir {
+				prev := m.dir
+				m.dir = parent
+				if err := m.load(base); err != nil {
+					m.dir, m.err = prev, err
+				}
+			}
+		}
+	}
+	m.scroll()
+	return m, nil
+}
+
+func (m *bigModel) rows() int {
+	if m.height == 0 {
+		return len(m.usage.Dirs)
+	}
+	return max(m.height-bigChrome, 1)
+}
+
+func (m *bigModel) scroll() {
+	m.off = clampOffset(m.off, m.cursor, m.rows())
+}
+
+func (m *bigModel) View() string {
+	out := "\n  " + peek.Breadcrumb(m.loc.title(m.dir), m.width-4) + "\n"
+	u := m.usage
+	u.Dirs = window(u.Dirs, m.off, m.rows())
+	out += peek.UsageRenderer{Width: m.width, Title: peek.HumanSize(m.usage.Total) + " in all"}.Box(u, m.cursor-m.off) + "\n"
+	if m.err != nil {
+		out += "  " + peek.ErrStyle.Render("error: "+peek.Sanitize(m.err.Error())) + "\n"
+	}
+	out += "  " + peek.CountStyle.Render("↑/↓ move  ·  enter open  ·  backspace up  ·  q quit")
+	return out
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// bookmark is a saved location, listed with peek @name.
+type bookmark struct {
+	name string
+	path string // absolute locally; remote targets as given
+}
+
+// runBookmark handles `peek bookmark add|rm|list ...`.
+func runBookmark(args []string) {
+	fl := newFlagSet("peek bookmark add NAME [path]", "peek bookmark rm NAME", "peek bookmark [list]")
+	fl.intro = []string{"Bookmarked dirs are listed with peek @NAME, or peek @NAME/sub/dir."}
+	pos := fl.parse(args)
+	applyTheme()
+
+	marks, err := loadBookmarks()
+	if err != nil {
+		fatal(err)
+	}
+	action := "list"
+	if len(pos) > 0 {
+		action, pos = pos[0], pos[1:]
+	}
+	switch {
+	case action == "list" && len(pos) == 0:
+		printBookmarks(marks)
+		return
+	case action == "add" && (len(pos) == 1 || len(pos) == 2):
+		name := strings.TrimPrefix(pos[0], "@")
+		if name == "" || strings.ContainsAny(name, "/\\ \t") {
+			fatal(fmt.Errorf("bookmark names can't be empty or hold slashes or spaces: %q", pos[0]))
+		}
+		target := "."
+		if len(pos) == 2 {
+			target = pos[1]
+		}
+		if target, err = bookmarkTarget(target); err != nil {
+			fatal(err)
+		}
+		marks = slices.DeleteFunc(marks, func(b bookmark) bool { return b.name == name })
+		marks = append(marks, bookmark{name, target})
+		slices.SortFunc(marks, func(a, b bookmark) int { return strings.Compare(a.name, b.name) })
+		if err := saveBookmarks(marks); err != nil {
+			fatal(err)
+		}
+		fmt.Println("  " + peek.CountStyle.Render("@"+name+" → "+peek.Sanitize(target)))
+		return
+	case action == "rm" && len(pos) == 1:
+		name := strings.TrimPrefix(pos[0], "@")
+		n := len(marks)
+		if marks = slices.DeleteFunc(marks, func(b bookmark) bool { return b.name == name }); len(marks) == n {
+			fatal(fmt.Errorf("no bookmark @%s", name))
+		}
+		if err := saveBookmarks(marks); err != nil {
+			fatal(err)
+		}
+		fmt.Println("  " + peek.CountStyle.Render("removed @"+name))
+		return
+	}
+	fatal(fmt.Errorf("usage: peek bookmark add NAME [path] | rm NAME | list"))
+}
+
+// bookmarkTarget is what a bookmark of target keeps: remote targets as
+// given, local dirs as absolute paths.
+func bookmarkTarget(target string) (string, error) {
+	if _, _, ok := parseS3(target); ok {
+		return target, nil
+	}
+	if _, ok := parseRemote(target); ok {
+		return target, nil
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(abs); err != nil {
+		return "", err
+	} else if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", target)
+	}
+	return abs, nil
+}
+
+func printBookmarks(marks []bookmark) {
+	if len(marks) == 0 {
+		fmt.Println("  " + peek.CountStyle.Render("no bookmarks yet (peek bookmark add NAME [path])"))
+		return
+	}
+	width := 0
+	for _, b := range marks {
+		width = max(width, len(b.name)+1)
+	}
+	for _, b := range marks {
+		at := fmt.Sprintf("%-*s", width, "@"+b.name)
+		fmt.Println("  " + peek.TitleStyle.Render(at) + "  " + peek.Sanitize(b.path))
+	}
+}
+
+// expandBookmark turns @name, or @name/sub/dir, into the bookmarked
+// path. Other args, and ones naming a file that exists, are left alone.
+func expandBookmark(arg string) string {
+	rest, ok := strings.CutPrefix(arg, "@")
+	if !ok {
+		return arg
+	}
+	name, sub, _ := strings.Cut(rest, "/")
+	marks, err := loadBookmarks()
+	if err != nil {
+		fatal(err)
+	}
+	for _, b := range marks {
+		if b.name != name {
+			continue
+		}
+		if sub == "" {
+			return b.path
+		}
+		if _, _, ok := parseS3(b.path); ok {
+			return strings.TrimSuffix(b.path, "/") + "/" + sub
+		}
+		if _, ok := parseRemote(b.path); ok {
+			return strings.TrimSuffix(b.path, "/") + "/" + sub
+		}
+		return filepath.Join(b.path, filepath.FromSlash(sub))
+	}
+	if _, err := os.Lstat(arg); err == nil {
+		return arg
+	}
+	fatal(fmt.Errorf("no bookmark @%s (see peek bookmark list)", name))
+	return ""
+}
+
+// loadBookmarks reads the bookmarks file, a name and a path per line
+// separated by a tab; a missing file is not an error.
+func loadBookmarks() ([]bookmark, error) {
+	file := configFile("bookmarks")
+	if file == "" {
+		return nil, nil
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var marks []bookmark
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if name, p, ok := strings.Cut(sc.Text(), "\t"); ok && name != "" {
+			marks = append(marks, bookmark{name, p})
+		}
+	}
+	return marks, sc.Err()
+}
+
+func saveBookmarks(marks []bookmark) error {
+	var b strings.Builder
+	for _, m := range marks {
+		b.WriteString(m.name + "\t" + m.path + "\n")
+	}
+	return writeConfigFile("bookmarks", b.String())
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// How much of a file peek cat reads and draws: text or binary, and
+// images, which must be read whole to be decoded.
+const (
+	maxCat   = 4 << 20
+	maxImage = 64 << 20
+)
+
+// graphicsMode is how images are drawn: the --graphics value, else the
+// protocol the terminal is known for, else blocks.
+var graphicsMode = ""
+
+// runCat handles `peek cat [options] FILE`.
+func runCat(args []string) {
+	scroll := false
+	dump := peek.HexBytes
+
+	fl := newFlagSet("peek cat [options] FILE")
+	fl.intro = []string{"Also what `peek FILE` does for a file that isn't an archive."}
+	fl.bool(&scroll, "", "scroll", "page the file instead of printing it")
+	fl.value("", "graphics", "MODE", "how to draw images: auto (default), kitty, sixel or blocks", func(v string) { graphicsMode = parseGraphics(v) })
+	fl.value("n", "bytes", "N", fmt.Sprintf("bytes of a binary file to hexdump, e.g. 4K (default %d)", peek.HexBytes), func(v string) { dump = int(parseSize(v)) })
+	pos := fl.parse(args)
+	if len(pos) == 0 {
+		fatal(fmt.Errorf("peek cat needs a file"))
+	}
+	target := onePath(pos)
+
+	applyTheme()
+	var out io.Writer = os.Stdout
+	var buf bytes.Buffer
+	if scroll {
+		out = &buf
+	}
+	if err := catFile(out, target, dump); err != nil {
+		fatal(err)
+	}
+	if scroll {
+		if err := page(buf.String()); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// catFile draws the file target on out: highlighted text, or the first
+// dump bytes of a binary file as a hexdump.
+func catFile(out io.Writer, target string, dump int) error {
+	loc, err := locate(&peek.Scanner{}, target)
+	if err != nil {
+		return err
+	}
+	defer loc.Close()
+	dir, name := filepath.Dir(loc.dir), filepath.Base(loc.dir)
+	if loc.remote() {
+		dir, name = path.Dir(loc.dir), path.Base(loc.dir)
+	}
+	limit := int64(maxCat)
+	if peek.KindOf(peek.Entry{Ext: strings.TrimPrefix(filepath.Ext(name), ".")}) == peek.KindImage {
+		limit = maxImage
+	}
+	src, size, err := loc.scanner.ReadHead(dir, name, limit)
+	if err != nil {
+		return loc.fail(err)
+	}
+	r := peek.CatRenderer{Width: termWidth(), Title: target, Name: name, Size: size, Dump: dump, Graphics: graphics(), Rows: termHeight()}
+	return r.Render(out, src)
+}
+
+// graphics picks how to draw images, or "" when output is plain.
+func graphics() string {
+	if plainOutput() {
+		return ""
+	}
+	if graphicsMode != "" {
+		return graphicsMode
+	}
+	term, prog := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" ||
+		prog == "WezTerm" || prog == "ghostty":
+		return peek.GraphicsKitty
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel"):
+		return peek.GraphicsSixel
+	}
+	return peek.GraphicsBlocks
+}
+
+func parseGraphics(s string) string {
+	switch s {
+	case "auto":
+		return ""
+	case peek.GraphicsKitty, peek.GraphicsSixel, peek.GraphicsBlocks:
+		return s
+	}
+	fatal(fmt.Errorf("unknown graphics mode %q (auto, kitty, sixel, blocks)", s))
+	return ""
+}
+
+// isFile reports whether target is a local file to draw with catFile
+// rather than list: not a dir, and not an archive to look inside.
+func isFile(target string) bool {
+	info, err := os.Stat(target)
+	return err == nil && !info.IsDir() && !peek.IsArchive(target)
+}
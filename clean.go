@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runClean handles `peek clean [options] [path]`.
+func runClean(args []string) {
+	remove := false
+	yes := false
+
+	fl := newFlagSet("peek clean [options] [path]")
+	fl.intro = []string{"Finds node_modules, __pycache__, .cache, target, build and dist dirs below",
+		"path and sums what deleting them would free. Nothing is deleted without --delete."}
+	fl.bool(&remove, "", "delete", "delete them, after asking")
+	fl.bool(&yes, "y", "yes", "with --delete, don't ask")
+	target := onePath(fl.parse(args))
+
+	applyTheme()
+	scanner := &peek.Scanner{Progress: &peek.Progress{}, Errors: &peek.ScanErrors{}}
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	defer loc.Close()
+	if remove && loc.remote() {
+		fatal(fmt.Errorf("--delete works on local dirs only"))
+	}
+	if remove && peek.InArchive(loc.dir) {
+		fatal(fmt.Errorf("--delete can't remove files inside an archive"))
+	}
+
+	done := showProgress(scanner.Progress)
+	dirs, err := loc.scanner.Cleanable(loc.dir)
+	done()
+	if err != nil {
+		fatal(loc.fail(err))
+	}
+	r := peek.PanelRenderer{Width: termWidth()}
+	fmt.Println()
+	fmt.Println("  " + peek.Breadcrumb(loc.title(absDir(loc, loc.dir)), r.Width-4))
+	if len(dirs) == 0 {
+		fmt.Println()
+		fmt.Println(peek.CountStyle.Render("  nothing to clean"))
+		fmt.Println()
+		return
+	}
+	if err := r.Render(os.Stdout, dirs); err != nil {
+		fatal(err)
+	}
+	printScanErrors(os.Stdout, true, r, scanner.Errors.List())
+
+	var total int64
+	for _, d := range dirs {
+		total += d.DirSize
+	}
+	if !remove {
+		fmt.Println(peek.CountStyle.Render(fmt.Sprintf("  %s to reclaim  ·  peek clean --delete to remove them", peek.HumanSize(total))))
+		fmt.Println()
+		return
+	}
+	if !yes && !confirm(fmt.Sprintf("Delete %s, freeing %s?", peek.Plural(len(dirs), "dir"), peek.HumanSize(total))) {
+		return
+	}
+	freed := int64(0)
+	for _, d := range dirs {
+		p := filepath.Join(loc.dir, filepath.FromSlash(d.Name))
+		err := os.RemoveAll(p)
+		// Count it only once it's really gone
+		if _, serr := os.Lstat(p); err == nil && !errors.Is(serr, fs.ErrNotExist) {
+			err = fmt.Errorf("%s: still there after deleting", p)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, peek.ErrStyle.Render("error: "+err.Error()))
+			continue
+		}
+		freed += d.DirSize
+	}
+	fmt.Println(peek.CountStyle.Render("  freed " + peek.HumanSize(freed)))
+}
+
+// confirm asks question on stderr and reports whether the answer on
+// stdin was yes.
+func confirm(question string) bool {
+	fmt.Fprint(os.Stderr, "  "+question+" [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runCode handles `peek code [options] [path]`.
+func runCode(args []string) {
+	showAll := false
+
+	fl := newFlagSet("peek code [options] [path]")
+	fl.bool(&showAll, "a", "all", "count hidden files and dirs")
+	target := onePath(fl.parse(args))
+
+	applyTheme()
+	cfg, _ := loadConfig()
+	scanner := &peek.Scanner{ShowAll: showAll, Prune: pruneNames(cfg), Progress: &peek.Progress{}}
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	done := showProgress(scanner.Progress)
+	stats, err := loc.scanner.Code(loc.dir)
+	done()
+	err = loc.fail(err)
+	loc.Close()
+	if err != nil {
+		fatal(err)
+	}
+	r := peek.CodeRenderer{Width: termWidth(), Title: target}
+	if err := r.Render(os.Stdout, stats); err != nil {
+		fatal(err)
+	}
+}
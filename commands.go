@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a `peek NAME ...` subcommand. Each parses its own args,
+// the global options included.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+// Filled in by init, as the listing's help refers back to it
+var commands []command
+
+func init() {
+	commands = []command{
+		{"tree", "recursive tree", runTree},
+		{"du", "the listing with recursive dir sizes (peek --du)", runDu},
+		{"stats", "files, size and share per extension", runStats},
+		{"code", "files, lines, comments and blanks per language", runCode},
+		{"cat", "a file, syntax highlighted", runCat},
+		{"open", "a file or dir in the system's app for it", runOpen},
+		{"heavy", "the largest files anywhere below a dir", runHeavy},
+		{"big", "subdirs ranked by recursive size, or browsed like ncdu", runBig},
+		{"pin", "keep files and dirs at the top of their panel", runPin},
+		{"bookmark", "save dirs to list later as peek @NAME", runBookmark},
+		{"z", "the dir zoxide ranks best for a query", runZ},
+		{"clean", "cache and build dirs, and the space deleting them frees", runClean},
+		{"diff", "compare two directory trees", runDiff},
+		{"snapshot", "save a listing, or diff against a saved one", runSnapshot},
+		{"manifest", "sha256sum-compatible checksums of a dir's files", runManifest},
+		{"verify", "check files against a manifest", runVerify},
+		{"shell-init", "a shell function that cds to where peek -i quits", runShellInit},
+		{"upgrade", "replace this binary with the latest release", runUpgrade},
+	}
+}
+
+func main() {
+	removeOldExecutable()
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if args[0] == "help" {
+			runHelp(args[1:])
+			return
+		}
+		if cmd := findCommand(args[0]); cmd != nil {
+			cmd.run(args[1:])
+			return
+		}
+	}
+	runList(args)
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// runHelp handles `peek help [command]`.
+func runHelp(args []string) {
+	if len(args) == 0 {
+		runList([]string{"-h"})
+		return
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fatal(fmt.Errorf("unknown command %q (see peek help)", args[0]))
+	}
+	cmd.run([]string{"-h"})
+}
+
+// commandList is the commands section of peek -h.
+func commandList() []string {
+	width := 0
+	for _, c := range commands {
+		width = max(width, len(c.name))
+	}
+	lines := []string{"Commands (peek help COMMAND for their options):"}
+	for _, c := range commands {
+		lines = append(lines, fmt.Sprintf("  %-*s  %s", width, c.name, c.summary))
+	}
+	return append(lines, "Options:")
+}
+
+// runDu handles `peek du [options] [path ...]`.
+func runDu(args []string) {
+	runList(append([]string{"--du"}, args...))
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// config mirrors config.toml in the user config dir, e.g.
+//
+//	theme = "mine"
+//	annotate = ["git-status-note"]
+//	max_children = 10000
+//	prune = ["node_modules", ".git"]
+//	pin = ["README.md", "Makefile"]
+//
+//	[themes.mine]
+//	base = "ocean"
+//	dir = "#ffaa00"
+type config struct {
+	Theme  string                       `toml:"theme"`
+	Themes map[string]map[string]string `toml:"themes"`
+
+	// Plugin commands whose output annotates each entry
+	Annotate []string `toml:"annotate"`
+
+	// Children counted per dir before giving up; 0 for no limit
+	MaxChildren *int `toml:"max_children"`
+
+	// Dir names --prune-common lists without reading; unset for
+	// peek.CommonPrune
+	Prune []string `toml:"prune"`
+
+	// Names listed first, and marked, in every dir that has them; see
+	// also peek pin
+	Pin []string `toml:"pin"`
+}
+
+// configPath honours $PEEK_CONFIG, then the platform config dir.
+func configPath() string {
+	if p := os.Getenv("PEEK_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "peek", "config.toml")
+}
+
+// configFile is the path of name beside the config file, where peek
+// keeps what its commands save, such as pins and bookmarks.
+func configFile(name string) string {
+	cfg := configPath()
+	if cfg == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(cfg), name)
+}
+
+// writeConfigFile replaces the configFile name with data, creating the
+// config dir if need be.
+func writeConfigFile(name, data string) error {
+	file := configFile(name)
+	if file == "" {
+		return fmt.Errorf("no config dir to keep %s in", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(data), 0o644)
+}
+
+// sizeCachePath is where --du keeps dir sizes between runs, in the
+// platform cache dir.
+func sizeCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "peek", "sizes.json")
+}
+
+// pruneNames is what --prune-common prunes: the config's list, else
+// peek.CommonPrune.
+func pruneNames(cfg config) []string {
+	if cfg.Prune != nil {
+		return cfg.Prune
+	}
+	return peek.CommonPrune
+}
+
+// loadConfig reads the config file; a missing file is not an error.
+func loadConfig() (config, error) {
+	var cfg config
+	path := configPath()
+	if path == "" {
+		return cfg, nil
+	}
+	if _, err := toml.DecodeFile(path, &cfg); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// resolveTheme picks the theme named by the flag, then the config, then
+// the default. Config themes start from their base (or the built-in of
+// the same name) and override individual roles.
+func resolveTheme(name string, cfg config) (peek.Theme, error) {
+	if name == "" {
+		name = cfg.Theme
+	}
+	if name == "" {
+		name = peek.DefaultTheme
+	}
+
+	if custom, ok := cfg.Themes[name]; ok {
+		base := custom["base"]
+		if base == "" {
+			base = name
+		}
+		t, ok := peek.Themes[base]
+		if !ok {
+			if base != name {
+				return t, fmt.Errorf("theme %q: unknown base %q", name, base)
+			}
+			t = peek.Themes[peek.DefaultTheme]
+		}
+		roles := make(map[string]string, len(custom))
+		for k, v := range custom {
+			if k != "base" {
+				roles[k] = v
+			}
+		}
+		t, err := t.Override(roles)
+		if err != nil {
+			return t, fmt.Errorf("theme %q: %w", name, err)
+		}
+		return t, nil
+	}
+
+	if t, ok := peek.Themes[name]; ok {
+		return t, nil
+	}
+	return peek.Theme{}, fmt.Errorf("unknown theme %q (built-in: %s)", name, strings.Join(peek.ThemeNames(), ", "))
+}
+
+// colorMode is the --color setting: auto, always or never.
+var colorMode = "auto"
+
+// themeName is the --theme setting; "" for the config's or the default.
+var themeName string
+
+// applyTheme loads the config and activates themeName, or plain output
+// when colorMode says so. Auto goes plain when stdout is not a terminal
+// or NO_COLOR is set.
+func applyTheme() {
+	cfg, err := loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	t, err := resolveTheme(themeName, cfg)
+	if err != nil {
+		fatal(err)
+	}
+	peek.SetTheme(t)
+
+	if colorMode == "always" {
+		lipgloss.SetColorProfile(termenv.TrueColor)
+	}
+	if plainOutput() {
+		peek.SetPlain()
+	}
+}
+
+func plainOutput() bool {
+	switch colorMode {
+	case "always":
+		return false
+	case "never":
+		return true
+	}
+	return os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(screen.Fd()))
+}
+
+// screen is the terminal peek draws on and sizes itself to: stdout, or
+// stderr when stdout is left for --print-dir.
+var screen = os.Stdout
+
+// drawOnStderr moves drawing, and color detection with it, to stderr.
+func drawOnStderr() {
+	screen = os.Stderr
+	r := lipgloss.NewRenderer(os.Stderr)
+	r.SetHasDarkBackground(true) // never query; see pkg/peek init
+	lipgloss.SetDefaultRenderer(r)
+}
+
+// lsColors returns the $LS_COLORS rules when enabled and output is styled.
+func lsColors(enabled bool) *peek.LSColors {
+	if !enabled || plainOutput() {
+		return nil
+	}
+	return peek.ParseLSColors(os.Getenv("LS_COLORS"))
+}
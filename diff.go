@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runDiff handles `peek diff [options] A B`.
+func runDiff(args []string) {
+	showAll := false
+	icons := peek.NoIcons
+
+	fl := newFlagSet("peek diff [options] A B")
+	fl.bool(&showAll, "a", "all", "compare hidden files too")
+	fl.optional("icons", "SET", "file icons: nerd (default) or emoji", func(v string) { icons = parseIcons(v) })
+	targets := fl.parse(args)
+	if len(targets) != 2 {
+		fatal(fmt.Errorf("diff needs two paths, got %d", len(targets)))
+	}
+
+	applyTheme()
+	scanner := &peek.Scanner{ShowAll: showAll}
+	a, err := locate(scanner, targets[0])
+	if err != nil {
+		fatal(err)
+	}
+	defer a.Close()
+	b, err := locate(scanner, targets[1])
+	if err != nil {
+		fatal(err)
+	}
+	defer b.Close()
+
+	d, err := peek.DiffDirs(a.scanner, a.dir, b.scanner, b.dir)
+	if err != nil {
+		fatal(err)
+	}
+	r := peek.PanelRenderer{Width: termWidth(), Icons: icons}
+	fmt.Printf("\n%s\n\n", r.Diff(d, targets[0], targets[1]))
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// flagSet parses GNU-style options: switches alone (-a) or combined
+// (-af), values as -d 3, -d3, --sort size or --sort=size, and "--" to
+// end the options. Anything else starting with "-" is an error.
+type flagSet struct {
+	usage []string // lines printed above the options by -h
+	intro []string // printed between the usage and the options
+	flags []*flagDef
+}
+
+type flagDef struct {
+	short    string // "a" for -a; "" when there is only the long form
+	long     string // "all" for --all
+	value    string // placeholder such as "KEY"; "" for switches
+	optional bool   // the value is given only as --long=VALUE
+	help     string
+	set      func(string) // gets the value; "" for switches
+}
+
+func newFlagSet(usage ...string) *flagSet {
+	return &flagSet{usage: usage}
+}
+
+// bool adds a switch that sets *p.
+func (f *flagSet) bool(p *bool, short, long, help string) {
+	f.action(short, long, help, func() { *p = true })
+}
+
+// action adds a switch that calls fn.
+func (f *flagSet) action(short, long, help string, fn func()) {
+	f.flags = append(f.flags, &flagDef{short: short, long: long, help: help, set: func(string) { fn() }})
+}
+
+// value adds an option taking a value, passed to set.
+func (f *flagSet) value(short, long, value, help string, set func(string)) {
+	f.flags = append(f.flags, &flagDef{short: short, long: long, value: value, help: help, set: set})
+}
+
+// optional adds a long option whose value may follow an "=": bare, set
+// gets "".
+func (f *flagSet) optional(long, value, help string, set func(string)) {
+	f.flags = append(f.flags, &flagDef{long: long, value: value, optional: true, help: help, set: set})
+}
+
+// parse applies the options in args and returns the other arguments.
+// -h prints the help and exits; a bad option is fatal.
+func (f *flagSet) parse(args []string) []string {
+	var pos []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(pos, args[i+1:]...)
+		case strings.HasPrefix(arg, "--"):
+			name, val, hasVal := strings.Cut(arg[2:], "=")
+			d := f.lookup(func(d *flagDef) bool { return d.long == name })
+			switch {
+			case d == nil:
+				fatal(fmt.Errorf("unknown option --%s (see -h)", name))
+			case d.value == "" && hasVal:
+				fatal(fmt.Errorf("--%s takes no value", name))
+			case d.value != "" && !hasVal && !d.optional:
+				if i+1 >= len(args) {
+					fatal(fmt.Errorf("--%s needs %s", name, d.value))
+				}
+				i++
+				val = args[i]
+			}
+			d.set(val)
+		case len(arg) > 1 && arg[0] == '-':
+			// Switches can share a dash; a value option ends the run and
+			// takes the rest of it, or else the next argument
+			for j := 1; j < len(arg); j++ {
+				c := arg[j : j+1]
+				d := f.lookup(func(d *flagDef) bool { return d.short == c })
+				if d == nil {
+					fatal(fmt.Errorf("unknown option -%s (see -h)", c))
+				}
+				if d.value == "" {
+					d.set("")
+					continue
+				}
+				val := arg[j+1:]
+				if val == "" {
+					if i+1 >= len(args) {
+						fatal(fmt.Errorf("-%s needs %s", c, d.value))
+					}
+					i++
+					val = args[i]
+				}
+				d.set(val)
+				break
+			}
+		default:
+			pos = append(pos, arg)
+		}
+	}
+	return pos
+}
+
+// onePath is the single path argument in pos, "." when there is none.
+func onePath(pos []string) string {
+	switch len(pos) {
+	case 0:
+		return "."
+	case 1:
+		return expandBookmark(pos[0])
+	}
+	fatal(fmt.Errorf("expected one path, got %d: %s", len(pos), strings.Join(pos, " ")))
+	return ""
+}
+
+// globalFlags are the options every command takes, after its own.
+func globalFlags() []*flagDef {
+	return []*flagDef{
+		{long: "color", value: "WHEN", help: "auto (default), always or never", set: func(v string) { colorMode = parseColor(v) }},
+		{long: "theme", value: "NAME", help: "color theme (" + strings.Join(peek.ThemeNames(), ", ") + ")", set: func(v string) { themeName = v }},
+	}
+}
+
+// all is the command's own flags, the global ones and -h.
+func (f *flagSet) all() []*flagDef {
+	flags := append(f.flags[:len(f.flags):len(f.flags)], globalFlags()...)
+	return append(flags, &flagDef{short: "h", long: "help", help: "this message", set: func(string) {
+		f.printHelp()
+		os.Exit(0)
+	}})
+}
+
+func (f *flagSet) lookup(match func(*flagDef) bool) *flagDef {
+	for _, d := range f.all() {
+		if match(d) {
+			return d
+		}
+	}
+	return nil
+}
+
+// printHelp lists the usage lines and then each option beside its help.
+func (f *flagSet) printHelp() {
+	for i, u := range f.usage {
+		if i == 0 {
+			fmt.Println("Usage: " + u)
+		} else {
+			fmt.Println("       " + u)
+		}
+	}
+	for _, l := range f.intro {
+		fmt.Println(l)
+	}
+	help := f.all()
+	names := make([]string, len(help))
+	width := 0
+	for i, d := range help {
+		names[i] = "    "
+		if d.short != "" {
+			names[i] = "-" + d.short + ", "
+		}
+		names[i] += "--" + d.long
+		switch {
+		case d.optional:
+			names[i] += "[=" + d.value + "]"
+		case d.value != "":
+			names[i] += " " + d.value
+		}
+		width = max(width, len(names[i]))
+	}
+	for i, d := range help {
+		fmt.Printf("  %-*s  %s\n", width, names[i], d.help)
+	}
+}
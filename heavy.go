@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runHeavy handles `peek heavy [options] [path]`.
+func runHeavy(args []string) {
+	showAll := false
+	n := 20
+	var match []string
+
+	fl := newFlagSet("peek heavy [options] [path]")
+	fl.bool(&showAll, "a", "all", "include hidden files")
+	fl.value("n", "count", "N", "how many files to show (default 20)", func(v string) { n = parseCount(v) })
+	fl.value("", "match", "GLOB", "only files matching GLOB (repeatable)", func(v string) { match = append(match, parseGlob(v)) })
+	target := onePath(fl.parse(args))
+
+	applyTheme()
+	scanner := &peek.Scanner{ShowAll: showAll, Match: match, Progress: &peek.Progress{}}
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	done := showProgress(scanner.Progress)
+	files, err := loc.scanner.Heavy(loc.dir, n)
+	done()
+	err = loc.fail(err)
+	r := peek.PanelRenderer{Width: termWidth()}
+	title := loc.title(absDir(loc, loc.dir))
+	loc.Close()
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Println()
+	fmt.Println("  " + peek.Breadcrumb(title, r.Width-4))
+	if err := r.Render(os.Stdout, files); err != nil {
+		fatal(err)
+	}
+}
+
+func parseCount(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		fatal(fmt.Errorf("invalid count %q", s))
+	}
+	return n
+}
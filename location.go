@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// location is a command-line target resolved to the scanner that can
+// read it: the local filesystem, SFTP, or S3.
+type location struct {
+	arg     string
+	scanner *peek.Scanner
+	dir     string // path to pass to scanner
+	label   string // remote root for titles, e.g. "me@host:"; "" locally
+	close   func()
+}
+
+// locate returns base unchanged for local paths. Remote targets get a
+// copy of base reading from the remote filesystem; call Close when done.
+func locate(base *peek.Scanner, arg string) (*location, error) {
+	loc := &location{arg: arg, scanner: base, dir: arg, close: func() {}}
+
+	if bucket, dir, ok := parseS3(arg); ok {
+		fsys, err := newS3FS(bucket)
+		if err != nil {
+			return nil, err
+		}
+		loc.scanner, loc.dir, loc.label = withFS(base, fsys), dir, "s3://"+bucket
+		return loc, nil
+	}
+
+	if rt, ok := parseRemote(arg); ok {
+		client, closeConn, err := dialSFTP(rt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rt.host, err)
+		}
+		// Relative paths start in the remote home dir
+		abs, err := client.RealPath(rt.path)
+		if rt.path == "" {
+			abs, err = client.Getwd()
+		}
+		if err != nil {
+			closeConn()
+			return nil, fmt.Errorf("%s: %w", rt, err)
+		}
+		loc.scanner, loc.dir, loc.label = withFS(base, sftpFS{client}), sftpName(abs), rt.login()+":"
+		loc.close = closeConn
+	}
+	return loc, nil
+}
+
+func withFS(base *peek.Scanner, fsys fs.FS) *peek.Scanner {
+	sc := *base
+	sc.FS = fsys
+	// A header read per image is a round trip each over the network,
+	// so image sizes are only read there with --media
+	sc.Dimensions = base.Dimensions && base.Media
+	return &sc
+}
+
+func (l *location) Close() {
+	l.close()
+}
+
+func (l *location) remote() bool {
+	return l.label != ""
+}
+
+// title shows dir, an fs path when remote, as the user would write it.
+func (l *location) title(dir string) string {
+	if !l.remote() {
+		return dir
+	}
+	if dir == "." {
+		return l.label + "/"
+	}
+	return l.label + "/" + dir
+}
+
+// fail names the whole target in fs errors, which remotely only carry
+// the path relative to the remote root.
+func (l *location) fail(err error) error {
+	if pe, ok := err.(*fs.PathError); ok && l.remote() {
+		pe.Path = l.arg
+	}
+	return err
+}
+
+// linkDir is the absolute dir for hyperlinks to entries of dir, or ""
+// when links are off, output is plain, or dir is remote or inside an
+// archive and so has no file:// URL.
+func (l *location) linkDir(enabled bool, dir string) string {
+	if !enabled || plainOutput() || l.remote() {
+		return ""
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	return abs
+}
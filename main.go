@@ -1,417 +1,629 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
-	"math"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"regexp"
+	"runtime/pprof"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/mattn/go-runewidth"
 	"golang.org/x/term"
 )
 
-const maxNameLen = 80
-
-var (
-	// Box border
-	boxBorder = lipgloss.RoundedBorder()
-
-	// Title inside box
-	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00ff66")).
-			Bold(true)
-
-	// Separator line
-	sepStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#003d1a"))
-
-	// Dir indicator
-	dirIndicator = lipgloss.NewStyle().Foreground(lipgloss.Color("#008844"))
-
-	// Dir names
-	dirNameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff66")).Bold(true)
-	dotDirStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#006633"))
-
-	// File names
-	fileNameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00dd55"))
-	dotFileStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#005c2e"))
-
-	// Metadata (size, child counts)
-	metaStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#008844"))
-
-	// Dot leader
-	dotLeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#002a11"))
-
-	// Symlinks
-	symNameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00ffaa")).Italic(true)
-
-	// Footer
-	countStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#006633"))
+// runList handles `peek [options] [path ...]`, the side-by-side listing.
+func runList(args []string) {
+	showAll := false
+	filesOnly := false
+	interactive := false
+	long := false
+	octal := false
+	times := false
+	useLSColors := false
+	hyperlinks := false
+	classify := false
+	follow := false
+	diskUsage := false
+	jsonOut := false
+	paths := false
+	print0 := false
+	var format *peek.TemplateRenderer
+	export := ""
+	var sep rune
+	scroll := false
+	allRows := false
+	disk := false
+	noCache := false
+	fast := false
+	timing := false
+	maxChildren := -1 // unset: the config's, else defaultMaxChildren
+	var timeout time.Duration
+	profile := ""
+	dupes := false
+	gitIgnore := false
+	prune := false
+	icons := peek.NoIcons
+	sortKey := peek.SortDefault
+	preview := 0
+	lines := false
+	sniff := false
+	textOnly := false
+	media := false
+	noReadme := false
+	zoxide := false
+	printDir, dirFile := false, ""
+	groupKinds := false
+	columns := 0
+	var newer, older time.Duration
+	var minSize, maxSize int64
+	var match []string
+	var nameRe *regexp.Regexp
+	var plugins []string
+
+	fl := newFlagSet("peek [options] [path|user@host:path|s3://bucket/prefix ...]", "peek COMMAND [options] ...")
+	fl.intro = commandList()
+	fl.bool(&showAll, "a", "all", "show hidden files")
+	fl.bool(&filesOnly, "f", "files", "files only")
+	fl.bool(&interactive, "i", "interactive", "browse with a cursor")
+	fl.bool(&long, "l", "long", "mode, owner, group and mtime in subtitles")
+	fl.action("", "octal", "long, with permissions as 755 instead of rwxr-xr-x", func() { long, octal = true, true })
+	fl.bool(&times, "", "times", "relative mtimes in subtitles (3h ago)")
+	fl.bool(&classify, "F", "classify", "mark executables with *")
+	fl.bool(&diskUsage, "", "du", "show recursive dir sizes")
+	fl.bool(&disk, "", "disk", "header with the volume's used and free space")
+	fl.bool(&follow, "L", "follow", "count and size through symlinked dirs")
+	fl.bool(&noCache, "", "no-cache", "size dirs afresh instead of from the --du cache")
+	fl.bool(&fast, "", "fast", "names only: no stat, link targets or child counts")
+	fl.value("", "max-children", "N", "stop counting a dir's children at N (0 = no limit, default 10000)", func(v string) { maxChildren = parseMaxChildren(v) })
+	fl.value("", "timeout", "DUR", "give up on stat/readdir calls slower than DUR (5s), as on dead mounts", func(v string) { timeout = parseTimeout(v) })
+	fl.optional("timing", "FILE", "print where the time went; =FILE also writes a CPU profile", func(v string) { timing, profile = true, v })
+	fl.bool(&jsonOut, "", "json", "print entries as JSON")
+	fl.bool(&paths, "", "plain", "one path per line and nothing else, for fzf and xargs")
+	fl.action("0", "print0", "plain, with paths ended by NUL bytes for xargs -0", func() { paths, print0 = true, true })
+	fl.bool(&gitIgnore, "", "git-ignore", "hide entries matched by .gitignore")
+	fl.bool(&prune, "", "prune-common", "don't read node_modules, .git, target and such (see Config)")
+	fl.bool(&dupes, "", "dupes", "group identical files below each path")
+	fl.action("", "csv", "one comma-separated row per entry", func() { sep = ',' })
+	fl.action("", "tsv", "one tab-separated row per entry", func() { sep = '\t' })
+	fl.value("", "export", "FMT", "write a Markdown table (md) or HTML page (html)", func(v string) { export = parseExport(v) })
+	fl.value("", "format", "TMPL", "Go template per entry, e.g. '{{.Name}}\\t{{human .Size}}'", func(v string) { format = parseFormat(v) })
+	fl.bool(&scroll, "", "scroll", "page output taller than the terminal")
+	fl.bool(&allRows, "", "all-rows", "don't cut panels to the terminal height")
+	fl.value("", "columns", "N", "grid of N columns per panel, or auto to fit the width", func(v string) { columns = parseColumns(v) })
+	fl.optional("icons", "SET", "file icons: nerd (default) or emoji", func(v string) { icons = parseIcons(v) })
+	fl.bool(&useLSColors, "", "ls-colors", "color names by $LS_COLORS")
+	fl.bool(&hyperlinks, "", "hyperlinks", "names link to their files (OSC 8)")
+	fl.bool(&zoxide, "", "zoxide", "rank the dirs listed, or opened with -i, up in zoxide")
+	fl.optional("print-dir", "FILE", "with -i, print the dir you quit in, or write it to FILE, to cd to (see peek shell-init)", func(v string) { printDir, dirFile = true, v })
+	fl.value("", "sort", "KEY", "name, size, mtime, ext, taken (EXIF date) or none", func(v string) { sortKey = parseSort(v) })
+	fl.value("", "match", "GLOB", "only files matching GLOB (repeatable)", func(v string) { match = append(match, parseGlob(v)) })
+	fl.value("", "group-by", "type", "split FILES into code, images, documents, ...", func(v string) { groupKinds = parseGroupBy(v) })
+	fl.value("", "regex", "RE", "only dirs and files whose name matches RE", func(v string) { nameRe = parseRegex(v) })
+	fl.value("", "newer-than", "AGE", "only entries modified within AGE (90m, 2d, 1w)", func(v string) { newer = parseAge(v) })
+	fl.value("", "older-than", "AGE", "only entries last modified over AGE ago", func(v string) { older = parseAge(v) })
+	fl.value("", "min-size", "SIZE", "only files of at least SIZE (10K, 1.5G)", func(v string) { minSize = parseSize(v) })
+	fl.value("", "max-size", "SIZE", "only files of at most SIZE", func(v string) { maxSize = parseSize(v) })
+	fl.value("", "annotate", "CMD", "run CMD PATH per entry, show its output (repeatable)", func(v string) { plugins = append(plugins, v) })
+	fl.value("", "preview", "N", "first N lines of each text file", func(v string) { preview = parsePreview(v) })
+	fl.bool(&lines, "", "lines", "line counts of text files beside their sizes")
+	fl.bool(&sniff, "", "sniff", "read each file's first 4 KB: style binaries apart, label types (PNG, ELF, gzip)")
+	fl.bool(&textOnly, "", "text-only", "only text files, as --sniff tells them")
+	fl.bool(&noReadme, "", "no-readme", "don't show the first lines of a dir's README above its panels")
+	fl.bool(&media, "", "media", "duration and codec of audio and video, capture date of photos, PDF pages; image sizes on S3 and SFTP")
+	fl.action("", "version", "print the version and build info", func() {
+		fmt.Println(versionString())
+		os.Exit(0)
+	})
+	targets := fl.parse(args)
+	started := time.Now()
+	stopProfile := func() {}
+	if profile != "" {
+		stopProfile = startProfile(profile)
+		defer stopProfile()
+	}
 
-	// Error
-	errStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff3334"))
-)
+	if fast && (diskUsage || long || times || lines || media || newer > 0 || older > 0 || minSize > 0 || maxSize > 0) {
+		fatal(fmt.Errorf("--fast lists names only; it can't go with --du, -l, --times, --lines, --media or the age and size filters"))
+	}
 
-type entry struct {
-	name     string
-	isDir    bool
-	isSym    bool
-	size     int64
-	dot      bool
-	ext      string
-	subDirs  int
-	subFiles int
-}
+	if f := os.Getenv("PEEK_PRINT_DIR"); !printDir && f != "" {
+		// Set by the peek shell-init wrappers, around every peek
+		printDir, dirFile = true, f
+	}
+	if printDir && dirFile == "" && interactive {
+		drawOnStderr()
+	}
+	if print0 && (jsonOut || format != nil || export != "" || sep != 0 || interactive) {
+		fatal(fmt.Errorf("--print0 writes paths only; it can't go with -i, --json, --csv, --tsv, --export or --format"))
+	}
 
-func main() {
-	showAll := false
-	filesOnly := false
-	target := "."
-
-	for _, arg := range os.Args[1:] {
-		switch arg {
-		case "-a", "--all":
-			showAll = true
-		case "-f", "--files":
-			filesOnly = true
-		case "-h", "--help":
-			fmt.Println("Usage: peek [options] [path]")
-			fmt.Println("  -a, --all     show hidden files")
-			fmt.Println("  -f, --files   files only")
-			fmt.Println("  -h, --help    this message")
-			return
-		default:
-			if !strings.HasPrefix(arg, "-") {
-				target = arg
-			}
+	applyTheme()
+	colors := lsColors(useLSColors)
+	var pruned, pinNames []string
+	if cfg, err := loadConfig(); err == nil {
+		plugins = append(cfg.Annotate, plugins...)
+		pinNames = cfg.Pin
+		if prune {
+			pruned = pruneNames(cfg)
+		}
+		if maxChildren < 0 && cfg.MaxChildren != nil {
+			maxChildren = max(*cfg.MaxChildren, 0)
 		}
 	}
-
-	entries, err := os.ReadDir(target)
+	if prune && pruned == nil {
+		pruned = peek.CommonPrune
+	}
+	if maxChildren < 0 {
+		maxChildren = defaultMaxChildren
+	}
+	pins, err := loadPins()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, errStyle.Render("error: "+err.Error()))
-		os.Exit(1)
+		fatal(err)
+	}
+	scanner := &peek.Scanner{
+		ShowAll:     showAll,
+		FilesOnly:   filesOnly,
+		DiskUsage:   diskUsage,
+		Sort:        sortKey,
+		Match:       match,
+		Owners:      long,
+		Follow:      follow,
+		MaxChildren: maxChildren,
+		Fast:        fast,
+		Lines:       lines,
+		Sniff:       sniff,
+		Dimensions:  !fast,
+		Media:       media || sortKey == peek.SortTaken,
+		TextOnly:    textOnly,
+		Timeout:     timeout,
+		GitIgnore:   gitIgnore,
+		MarkIgnored: !plainOutput(),
+		Prune:       pruned,
+		MinSize:     minSize,
+		MaxSize:     maxSize,
+		Regex:       nameRe,
+		Annotate:    annotator(plugins),
+		Pinned:      pinner(pinNames, pins),
+		Progress:    &peek.Progress{},
 	}
 
-	var dirs, files []entry
-	for _, e := range entries {
-		name := e.Name()
-		isDot := strings.HasPrefix(name, ".")
-
-		if isDot && !showAll {
-			continue
+	if timing {
+		scanner.Timing = &peek.Timing{}
+	}
+	if diskUsage && !noCache {
+		if file := sizeCachePath(); file != "" {
+			scanner.SizeCache = peek.OpenSizeCache(file)
+			defer scanner.SizeCache.Save()
 		}
+	}
+
+	now := time.Now()
+	if newer > 0 {
+		scanner.NewerThan = now.Add(-newer)
+	}
+	if older > 0 {
+		scanner.OlderThan = now.Add(-older)
+	}
+
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	for i, t := range targets {
+		targets[i] = expandBookmark(t)
+	}
 
-		info, err := e.Info()
+	if interactive {
+		dir, err := runInteractive(targets[0], scanner, peek.PanelRenderer{Icons: icons, Long: long, Octal: octal, Times: times, Classify: classify, Colors: colors}, hyperlinks, zoxide)
 		if err != nil {
-			continue
+			fatal(err)
 		}
+		if printDir && dir != "" {
+			if dirFile == "" {
+				fmt.Println(dir)
+			} else if err := os.WriteFile(dirFile, []byte(dir), 0o600); err != nil {
+				fatal(err)
+			}
+		}
+		return
+	}
 
-		isDir := e.IsDir()
-		isSym := e.Type()&os.ModeSymlink != 0
+	// Previews would break the cursor's row math, so only static listings get them
+	scanner.Preview = preview
 
-		if isSym {
-			resolved, err := filepath.EvalSymlinks(filepath.Join(target, name))
-			if err == nil {
-				ri, err := os.Stat(resolved)
-				if err == nil {
-					isDir = ri.IsDir()
+	// Panels are drawn unless another output format was picked
+	plain := !jsonOut && !paths && format == nil && export == "" && sep == 0
+
+	// With --scroll the listing is buffered and paged once complete
+	var out io.Writer = os.Stdout
+	var buf bytes.Buffer
+	if scroll && plain {
+		out = &buf
+	}
+
+	// Several targets are stacked as labeled sections; a failing one is
+	// reported and the rest are still listed.
+	labeled := len(targets) > 1
+	failed := false
+	for _, target := range targets {
+		if plain && isFile(target) {
+			if err := catFile(out, target, peek.HexBytes); err != nil {
+				if !labeled {
+					fatal(err)
 				}
+				fmt.Fprintln(os.Stderr, peek.ErrStyle.Render("error: "+err.Error()))
+				failed = true
 			}
+			continue
 		}
-
-		ext := ""
-		if !isDir {
-			ext = strings.TrimPrefix(filepath.Ext(name), ".")
+		panel := peek.PanelRenderer{
+			Width:      termWidth(),
+			Icons:      icons,
+			Long:       long,
+			Octal:      octal,
+			Times:      times,
+			Classify:   classify,
+			Bare:       fast,
+			GroupKinds: groupKinds,
+			Columns:    columns,
+			Colors:     colors,
 		}
-
-		it := entry{
-			name:  name,
-			isDir: isDir,
-			isSym: isSym,
-			size:  info.Size(),
-			dot:   isDot,
-			ext:   ext,
+		if !scroll && !allRows {
+			panel.MaxRows, panel.MoreHint = listRows(), "(--all-rows or -i)"
+			if disk && panel.MaxRows > 0 {
+				panel.MaxRows = max(panel.MaxRows-2, 3)
+			}
+		}
+		var r peek.Renderer
+		if jsonOut {
+			r = peek.JSONRenderer{}
+			if labeled {
+				r = peek.JSONRenderer{Path: target}
+			}
+		} else if paths {
+			r = peek.PathRenderer{Dir: pathPrefix(target), NUL: print0}
+		} else if sep != 0 {
+			r = peek.CSVRenderer{Comma: sep}
+		} else if export == "md" {
+			r = peek.MarkdownRenderer{Title: target}
+		} else if export == "html" {
+			r = peek.HTMLRenderer{Title: target}
+		} else if format != nil {
+			r = *format
 		}
 
-		if isDir && !filesOnly {
-			// Count immediate children
-			subEntries, err := os.ReadDir(filepath.Join(target, name))
-			if err == nil {
-				for _, se := range subEntries {
-					if !showAll && strings.HasPrefix(se.Name(), ".") {
-						continue
-					}
-					if se.IsDir() {
-						it.subDirs++
-					} else {
-						it.subFiles++
+		scanner.Errors = &peek.ScanErrors{}
+		loc, err := locate(scanner, target)
+		if err == nil {
+			var entries []peek.Entry
+			done := showProgress(scanner.Progress)
+			entries, panel.Hidden, err = loc.scanner.ScanHidden(loc.dir)
+			done()
+			if r == nil {
+				panel.LinkDir = loc.linkDir(hyperlinks, loc.dir)
+				if err == nil || labeled {
+					printTitle(out, loc, panel.Width, err == nil)
+				}
+				if disk && err == nil && !loc.remote() {
+					printVolume(out, loc.dir)
+				}
+				if zoxide && err == nil && !loc.remote() {
+					zoxideAdd(absDir(loc, loc.dir))
+				}
+				if err == nil && !noReadme {
+					if name, lines := loc.scanner.ReadmePreview(loc.dir, entries, readmeLines); name != "" {
+						fmt.Fprintln(out)
+						fmt.Fprintln(out, panel.TextColumn(name, lines))
+						if panel.MaxRows > 0 {
+							panel.MaxRows = max(panel.MaxRows-len(lines)-7, 3)
+						}
 					}
 				}
+				r = panel
+			}
+			if err == nil {
+				start := time.Now()
+				err = r.Render(out, entries)
+				scanner.Timing.Add(peek.PhaseRender, start)
+			}
+			if err == nil {
+				printScanErrors(out, plain, panel, scanner.Errors.List())
+			}
+			if err == nil && dupes && plain {
+				var groups []peek.DupeGroup
+				if groups, err = loc.scanner.Dupes(loc.dir); err == nil {
+					fmt.Fprintln(out, panel.Dupes(groups))
+				}
 			}
-			dirs = append(dirs, it)
-		} else if !isDir {
-			files = append(files, it)
+			err = loc.fail(err)
+			loc.Close()
+		}
+		if err != nil {
+			if !labeled {
+				fatal(err)
+			}
+			fmt.Fprintln(os.Stderr, peek.ErrStyle.Render("error: "+err.Error()))
+			failed = true
 		}
 	}
-
-	sortEntries := func(items []entry) {
-		sort.Slice(items, func(i, j int) bool {
-			return strings.ToLower(items[i].name) < strings.ToLower(items[j].name)
-		})
+	if timing {
+		printTiming(scanner.Timing, time.Since(started))
 	}
-	sortEntries(dirs)
-	// Sort files by decreasing size
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].size > files[j].size
-	})
-
-	if len(dirs) == 0 && len(files) == 0 {
-		fmt.Println(countStyle.Render("  empty"))
-		return
+	if scroll && plain {
+		if err := page(buf.String()); err != nil {
+			fatal(err)
+		}
 	}
-
-	// Terminal width
-	width := 80
-	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
-		width = w
+	if failed {
+		if scanner.SizeCache != nil {
+			scanner.SizeCache.Save()
+		}
+		stopProfile()
+		os.Exit(1)
 	}
+}
 
-	gap := 2
-	// Width() includes padding but not border; border adds 2
-	panelOuter := (width - gap) / 2
-	innerW := panelOuter - 2 // subtract border only
+// pathPrefix is what --plain puts before names listed in target:
+// nothing for "." or a bare "host:", else target ending in a separator.
+func pathPrefix(target string) string {
+	sep := string(filepath.Separator)
+	if _, _, ok := parseS3(target); ok {
+		sep = "/"
+	} else if _, ok := parseRemote(target); ok {
+		sep = "/"
+	}
+	switch {
+	case target == ".":
+		return ""
+	case strings.HasSuffix(target, sep) || strings.HasSuffix(target, "/") || sep == "/" && strings.HasSuffix(target, ":"):
+		return target
+	}
+	return target + sep
+}
 
-	if innerW < 20 {
-		innerW = 20
+// printTitle writes the listing's breadcrumb and, for a dir that was
+// read, badges for the kind of project it is.
+func printTitle(out io.Writer, loc *location, width int, read bool) {
+	badges := ""
+	if read {
+		badges = peek.ProjectBadges(loc.scanner.Project(loc.dir))
+	}
+	room := width - 4
+	if badges != "" {
+		room -= lipgloss.Width(badges) + 2
+		badges = "  " + badges
 	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "  "+peek.Breadcrumb(loc.title(absDir(loc, loc.dir)), room)+badges)
+}
 
-	nameMax := innerW - 4 // subtract horizontal padding (2 each side)
-	if nameMax > maxNameLen {
-		nameMax = maxNameLen
+// printScanErrors lists what couldn't be read below the panels, or on
+// stderr when the output is data.
+func printScanErrors(out io.Writer, panels bool, r peek.PanelRenderer, errs []error) {
+	if !panels {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, peek.ErrStyle.Render("error: "+err.Error()))
+		}
+		return
 	}
+	if section := r.Errors(errs); section != "" {
+		fmt.Fprintln(out, section)
+	}
+}
 
-	boxStyle := lipgloss.NewStyle().
-		Border(boxBorder).
-		BorderForeground(lipgloss.Color("#004d26")).
-		Padding(1, 2).
-		Width(innerW)
+// printTiming reports to stderr the time per phase of the listing.
+func printTiming(t *peek.Timing, total time.Duration) {
+	var parts []string
+	other := total
+	for _, p := range peek.Phases() {
+		parts = append(parts, fmt.Sprintf("%s %s", p, t.Get(p).Round(time.Microsecond)))
+		other -= t.Get(p)
+	}
+	parts = append(parts, fmt.Sprintf("other %s", max(other, 0).Round(time.Microsecond)))
+	fmt.Fprintln(os.Stderr, peek.CountStyle.Render("timing: "+strings.Join(parts, "  ·  ")))
+	fmt.Fprintln(os.Stderr, peek.CountStyle.Render(fmt.Sprintf("total:  %s", total.Round(time.Microsecond))))
+}
 
-	// Build dir content
-	dirHeader := makeHeader("DIRS", nameMax)
-	dirContent := buildDirContent(dirs, nameMax)
-	// Build file content
-	fileHeader := makeHeader("FILES", nameMax)
-	fileContent := buildFileContent(files, nameMax)
+// startProfile writes a CPU profile to file until the returned func is
+// called.
+func startProfile(file string) func() {
+	f, err := os.Create(file)
+	if err != nil {
+		fatal(err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		fatal(err)
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		f.Close()
+	}
+}
 
-	// Single panel modes
-	if filesOnly || len(dirs) == 0 {
-		wideInner := width - 2 // full width minus border
-		if wideInner < 20 {
-			wideInner = 20
-		}
-		wideMax := wideInner - 4 // minus padding
-		if wideMax > maxNameLen {
-			wideMax = maxNameLen
-		}
-		wideBox := lipgloss.NewStyle().
-			Border(boxBorder).
-			BorderForeground(lipgloss.Color("#004d26")).
-			Padding(1, 2).
-			Width(wideInner)
-		fc := buildFileContent(files, wideMax)
-		fh := makeHeader("FILES", wideMax)
-		panel := wideBox.Render(fh + fc)
-		fmt.Println()
-		fmt.Println(panel)
-		fmt.Println()
-		printFooter(len(dirs), len(files))
-		return
+// absDir is dir made absolute when it is local, for titles.
+func absDir(loc *location, dir string) string {
+	if loc.remote() {
+		return dir
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
 	}
+	return dir
+}
 
-	if len(files) == 0 {
-		wideInner := width - 2 // full width minus border
-		if wideInner < 20 {
-			wideInner = 20
-		}
-		wideMax := wideInner - 4 // minus padding
-		if wideMax > maxNameLen {
-			wideMax = maxNameLen
-		}
-		wideBox := lipgloss.NewStyle().
-			Border(boxBorder).
-			BorderForeground(lipgloss.Color("#004d26")).
-			Padding(1, 2).
-			Width(wideInner)
-		dc := buildDirContent(dirs, wideMax)
-		dh := makeHeader("DIRS", wideMax)
-		panel := wideBox.Render(dh + dc)
-		fmt.Println()
-		fmt.Println(panel)
-		fmt.Println()
-		printFooter(len(dirs), len(files))
+// printVolume writes the header for the volume holding dir, or nothing
+// when its space can't be read, as inside an archive.
+func printVolume(w io.Writer, dir string) {
+	v, err := peek.VolumeOf(dir)
+	if err != nil {
 		return
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, peek.VolumeHeader(v))
+}
 
-	// Two panels side by side
-	leftPanel := boxStyle.Render(dirHeader + dirContent)
-	rightPanel := boxStyle.Render(fileHeader + fileContent)
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, peek.ErrStyle.Render("error: "+err.Error()))
+	os.Exit(1)
+}
+
+// readmeLines is how much of a dir's README is shown.
+const readmeLines = 4
 
-	joined := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, strings.Repeat(" ", gap), rightPanel)
+// Lines of a listing outside the panel rows: the title and blank lines
+// around it, box border and padding, panel header, footer and the
+// prompt after it.
+const listChrome = 14
 
-	fmt.Println()
-	fmt.Println(joined)
-	fmt.Println()
-	printFooter(len(dirs), len(files))
+// listRows is how many panel rows fit on the terminal, or 0 when output
+// is not to a terminal.
+func listRows() int {
+	_, h, err := term.GetSize(int(screen.Fd()))
+	if err != nil || h <= 0 {
+		return 0
+	}
+	return max(h-listChrome, 3)
 }
 
-func makeHeader(title string, lineWidth int) string {
-	line := sepStyle.Render(strings.Repeat("─", lineWidth))
-	return titleStyle.Render(title) + "\n" + line + "\n"
+func termHeight() int {
+	height := 24
+	if _, h, err := term.GetSize(int(screen.Fd())); err == nil && h > 0 {
+		height = h
+	}
+	return height
 }
 
-func buildDirContent(dirs []entry, lineWidth int) string {
-	var lines []string
-	for _, d := range dirs {
-		sub := dirSubtitle(d.subDirs, d.subFiles)
-		// ▸ prefix takes 2 chars
-		nameLimit := lineWidth - runewidth.StringWidth(sub) - 5
-		if nameLimit < 8 {
-			nameLimit = 8
-		}
-		name := truncate(d.name, nameLimit)
-
-		var styledName string
-		switch {
-		case d.isSym:
-			styledName = symNameStyle.Render(name)
-		case d.dot:
-			styledName = dotDirStyle.Render(name)
-		default:
-			styledName = dirNameStyle.Render(name)
-		}
+func termWidth() int {
+	width := 80
+	if w, _, err := term.GetSize(int(screen.Fd())); err == nil && w > 0 {
+		width = w
+	}
+	return width
+}
 
-		prefix := dirIndicator.Render("▸") + " "
-		dots := lineWidth - runewidth.StringWidth(name) - runewidth.StringWidth(sub) - 2
-		if dots < 3 {
-			dots = 3
-		}
-		leader := " " + dotLeaderStyle.Render(strings.Repeat("·", dots-2)) + " "
-		lines = append(lines, prefix+styledName+leader+metaStyle.Render(sub))
+func parseIcons(s string) peek.IconSet {
+	switch s {
+	case "", "nerd":
+		return peek.NerdIcons
+	case "emoji":
+		return peek.EmojiIcons
+	case "none":
+		return peek.NoIcons
 	}
-	return strings.Join(lines, "\n")
+	fatal(fmt.Errorf("unknown icon set %q (nerd, emoji, none)", s))
+	return peek.NoIcons
 }
 
-func buildFileContent(files []entry, lineWidth int) string {
-	var lines []string
-	for _, f := range files {
-		sz := humanSize(f.size)
-		nameLimit := lineWidth - runewidth.StringWidth(sz) - 5
-		if nameLimit < 8 {
-			nameLimit = 8
-		}
-		name := truncate(f.name, nameLimit)
-
-		var styledName string
-		switch {
-		case f.isSym:
-			styledName = symNameStyle.Render(name)
-		case f.dot:
-			styledName = dotFileStyle.Render(name)
-		default:
-			styledName = fileNameStyle.Render(name)
-		}
+func parseSort(s string) peek.SortKey {
+	k, err := peek.ParseSortKey(s)
+	if err != nil {
+		fatal(err)
+	}
+	return k
+}
 
-		// 2 chars for prefix space alignment with dir panel
-		prefix := "  "
-		dots := lineWidth - runewidth.StringWidth(name) - runewidth.StringWidth(sz) - 2
-		if dots < 3 {
-			dots = 3
-		}
-		leader := " " + dotLeaderStyle.Render(strings.Repeat("·", dots-2)) + " "
-		lines = append(lines, prefix+styledName+leader+metaStyle.Render(sz))
+func parseGlob(s string) string {
+	if _, err := filepath.Match(s, ""); err != nil {
+		fatal(fmt.Errorf("bad pattern %q: %w", s, err))
 	}
-	return strings.Join(lines, "\n")
+	return s
 }
 
-func printFooter(dirCount, fileCount int) {
-	parts := []string{}
-	if dirCount > 0 {
-		s := fmt.Sprintf("%d dir", dirCount)
-		if dirCount > 1 {
-			s += "s"
-		}
-		parts = append(parts, s)
+func parsePreview(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		fatal(fmt.Errorf("invalid line count %q", s))
 	}
-	if fileCount > 0 {
-		s := fmt.Sprintf("%d file", fileCount)
-		if fileCount > 1 {
-			s += "s"
-		}
-		parts = append(parts, s)
+	return n
+}
+
+// Dirs with more children than this show as "10k+ entries"
+const defaultMaxChildren = 10000
+
+func parseMaxChildren(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		fatal(fmt.Errorf("invalid child limit %q", s))
 	}
-	fmt.Println("  " + countStyle.Render(strings.Join(parts, "  ·  ")))
-	fmt.Println()
+	return n
 }
 
-func truncate(s string, max int) string {
-	if max < 4 {
-		max = 4
+func parseTimeout(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		fatal(fmt.Errorf("invalid timeout %q (e.g. 5s, 500ms)", s))
 	}
-	if runewidth.StringWidth(s) <= max {
-		return s
+	return d
+}
+
+func parseColumns(s string) int {
+	if s == "auto" {
+		return peek.AutoColumns
 	}
-	// Truncate rune-by-rune to respect display width
-	w := 0
-	for i, r := range s {
-		rw := runewidth.RuneWidth(r)
-		if w+rw > max-1 {
-			return s[:i] + "…"
-		}
-		w += rw
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		fatal(fmt.Errorf("invalid column count %q (want a number or auto)", s))
 	}
-	return s
+	return n
 }
 
-func dirSubtitle(subDirs, subFiles int) string {
-	if subDirs == 0 && subFiles == 0 {
-		return "empty"
+func parseAge(s string) time.Duration {
+	d, err := peek.ParseAge(s)
+	if err != nil {
+		fatal(err)
 	}
-	var parts []string
-	if subDirs > 0 {
-		s := fmt.Sprintf("%d dir", subDirs)
-		if subDirs > 1 {
-			s += "s"
-		}
-		parts = append(parts, s)
+	return d
+}
+
+func parseSize(s string) int64 {
+	n, err := peek.ParseSize(s)
+	if err != nil {
+		fatal(err)
 	}
-	if subFiles > 0 {
-		s := fmt.Sprintf("%d file", subFiles)
-		if subFiles > 1 {
-			s += "s"
-		}
-		parts = append(parts, s)
+	return n
+}
+
+func parseRegex(s string) *regexp.Regexp {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		fatal(fmt.Errorf("bad regex %q: %w", s, err))
 	}
-	return strings.Join(parts, ", ")
+	return re
 }
 
-func humanSize(b int64) string {
-	if b == 0 {
-		return "0 B"
+func parseGroupBy(s string) bool {
+	if s != "type" {
+		fatal(fmt.Errorf("unknown group key %q (type)", s))
 	}
-	units := []string{"B", "K", "M", "G", "T"}
-	i := int(math.Log(float64(b)) / math.Log(1024))
-	if i >= len(units) {
-		i = len(units) - 1
+	return true
+}
+
+// parseFormat reads a --format template, turning \t, \n and \\ into the
+// characters they name since shells pass them through literally.
+func parseFormat(s string) *peek.TemplateRenderer {
+	s = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n").Replace(s)
+	r, err := peek.NewTemplateRenderer(s)
+	if err != nil {
+		fatal(fmt.Errorf("bad format: %w", err))
 	}
-	val := float64(b) / math.Pow(1024, float64(i))
-	if i == 0 {
-		return fmt.Sprintf("%d B", b)
+	return &r
+}
+
+func parseExport(s string) string {
+	if s != "md" && s != "html" {
+		fatal(fmt.Errorf("unknown export format %q (md, html)", s))
 	}
-	if val >= 10 {
-		return fmt.Sprintf("%d %s", int(val), units[i])
+	return s
+}
+
+func parseColor(s string) string {
+	if s != "auto" && s != "always" && s != "never" {
+		fatal(fmt.Errorf("unknown color mode %q (auto, always, never)", s))
 	}
-	return fmt.Sprintf("%.1f %s", val, units[i])
+	return s
 }
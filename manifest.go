@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runManifest handles `peek manifest [options] [path]`.
+func runManifest(args []string) {
+	showAll := false
+	out := ""
+
+	fl := newFlagSet("peek manifest [options] [path]")
+	fl.bool(&showAll, "a", "all", "include hidden files")
+	fl.value("o", "output", "FILE", "write to FILE instead of stdout", func(v string) { out = v })
+	target := onePath(fl.parse(args))
+
+	applyTheme()
+
+	// Buffered so a manifest written into the listed dir doesn't list itself
+	var buf bytes.Buffer
+	scanner := &peek.Scanner{ShowAll: showAll}
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	err = loc.fail(loc.scanner.Manifest(loc.dir, &buf))
+	loc.Close()
+	if err != nil {
+		fatal(err)
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = os.WriteFile(out, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// runVerify handles `peek verify [options] MANIFEST`, exiting 1 when a
+// file is missing or its checksum differs.
+func runVerify(args []string) {
+	icons := peek.NoIcons
+
+	fl := newFlagSet("peek verify [options] MANIFEST")
+	fl.optional("icons", "SET", "file icons: nerd (default) or emoji", func(v string) { icons = parseIcons(v) })
+	pos := fl.parse(args)
+	if len(pos) != 1 {
+		fatal(fmt.Errorf("verify needs one manifest file"))
+	}
+	manifest := pos[0]
+
+	applyTheme()
+	scanner := &peek.Scanner{}
+	entries, bad, err := scanner.Verify(manifest)
+	if err != nil {
+		fatal(err)
+	}
+	r := peek.PanelRenderer{Width: termWidth(), Icons: icons}
+	if err := r.Render(os.Stdout, entries); err != nil {
+		fatal(err)
+	}
+	if bad > 0 {
+		msg := fmt.Sprintf("  %d files failed verification", bad)
+		if bad == 1 {
+			msg = "  1 file failed verification"
+		}
+		fmt.Fprintln(os.Stderr, peek.ErrStyle.Render(msg))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runOpen handles `peek open [path ...]`.
+func runOpen(args []string) {
+	fl := newFlagSet("peek open [path ...]")
+	fl.intro = []string{"Opens each path, . by default, in the app the system has for it."}
+	paths := fl.parse(args)
+	applyTheme()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	failed := false
+	for _, p := range paths {
+		if err := openDefault(expandBookmark(p)); err != nil {
+			fmt.Fprintln(os.Stderr, peek.ErrStyle.Render("error: "+err.Error()))
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// openDefault hands the local file or dir p to the system's opener:
+// open on macOS, the shell's file handler on Windows, xdg-open
+// elsewhere. It doesn't wait for the app.
+func openDefault(p string) error {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return err
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", abs)
+	case "windows":
+		// Not cmd /c start, which would parse & | ^ and %VAR% in the name
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", abs)
+	default:
+		cmd = exec.Command("xdg-open", abs)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("opening %s: %w", p, err)
+	}
+	go cmd.Wait()
+	return nil
+}
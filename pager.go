@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// page shows out in a scrollable viewport when it is taller than the
+// terminal, and prints it as-is otherwise or when stdout is not a tty.
+func page(out string) error {
+	fd := int(os.Stdout.Fd())
+	_, height, err := term.GetSize(fd)
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if err != nil || !term.IsTerminal(fd) || len(lines) < height {
+		fmt.Print(out)
+		return nil
+	}
+	_, err = tea.NewProgram(&pager{lines: lines, height: height}, tea.WithAltScreen()).Run()
+	return err
+}
+
+type pager struct {
+	lines  []string
+	off    int
+	height int
+}
+
+func (p *pager) Init() tea.Cmd {
+	return nil
+}
+
+// rows is how many content lines fit above the status line.
+func (p *pager) rows() int {
+	return max(p.height-1, 1)
+}
+
+func (p *pager) scrollTo(off int) {
+	p.off = max(min(off, len(p.lines)-p.rows()), 0)
+}
+
+func (p *pager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.height = msg.Height
+		p.scrollTo(p.off)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return p, tea.Quit
+		case "up", "k":
+			p.scrollTo(p.off - 1)
+		case "down", "j", "enter":
+			p.scrollTo(p.off + 1)
+		case "pgup", "b":
+			p.scrollTo(p.off - p.rows())
+		case "pgdown", " ", "f":
+			p.scrollTo(p.off + p.rows())
+		case "home", "g":
+			p.scrollTo(0)
+		case "end", "G":
+			p.scrollTo(len(p.lines))
+		}
+	}
+	return p, nil
+}
+
+func (p *pager) View() string {
+	end := min(p.off+p.rows(), len(p.lines))
+	status := fmt.Sprintf("  %d–%d of %d  ·  ↑/↓ scroll  ·  pgup/pgdn page  ·  q quit", p.off+1, end, len(p.lines))
+	return strings.Join(p.lines[p.off:end], "\n") + "\n" + peek.CountStyle.Render(status)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runPin handles `peek pin [options] [path ...]`.
+func runPin(args []string) {
+	remove := false
+	fl := newFlagSet("peek pin [options] [path ...]")
+	fl.intro = []string{
+		"Pinned files and dirs come first in their panel, marked with •.",
+		"Without paths, lists the pins. Names in the config's pin list are",
+		"pinned in every dir.",
+	}
+	fl.bool(&remove, "d", "remove", "unpin the paths instead")
+	paths := fl.parse(args)
+	applyTheme()
+
+	pins, err := loadPins()
+	if err != nil {
+		fatal(err)
+	}
+	if len(paths) == 0 {
+		if remove {
+			fatal(fmt.Errorf("usage: peek pin --remove path ..."))
+		}
+		if len(pins) == 0 {
+			fmt.Println("  " + peek.CountStyle.Render("no pins yet (peek pin PATH)"))
+		}
+		for _, p := range pins {
+			fmt.Println("  " + peek.Sanitize(p))
+		}
+		return
+	}
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			fatal(err)
+		}
+		if remove {
+			if !slices.Contains(pins, abs) {
+				fatal(fmt.Errorf("%s is not pinned", p))
+			}
+			pins = slices.DeleteFunc(pins, func(q string) bool { return q == abs })
+			continue
+		}
+		if _, err := os.Lstat(abs); err != nil {
+			fatal(err)
+		}
+		if !slices.Contains(pins, abs) {
+			pins = append(pins, abs)
+		}
+	}
+	if err := savePins(pins); err != nil {
+		fatal(err)
+	}
+	verb := "pinned"
+	if remove {
+		verb = "unpinned"
+	}
+	fmt.Println("  " + peek.CountStyle.Render(verb+" "+peek.Plural(len(paths), "path")))
+}
+
+// loadPins reads the pins file, a path per line; a missing file is
+// not an error.
+func loadPins() ([]string, error) {
+	file := configFile("pins")
+	if file == "" {
+		return nil, nil
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var pins []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if p := strings.TrimSpace(sc.Text()); p != "" {
+			pins = append(pins, p)
+		}
+	}
+	return pins, sc.Err()
+}
+
+func savePins(pins []string) error {
+	var b strings.Builder
+	for _, p := range pins {
+		b.WriteString(p + "\n")
+	}
+	return writeConfigFile("pins", b.String())
+}
+
+// pinner reports the entries named in the config's pin list, and those
+// at the paths pinned with peek pin. Remote entries go by name alone.
+func pinner(names, paths []string) func(string, peek.Entry) bool {
+	if len(names) == 0 && len(paths) == 0 {
+		return nil
+	}
+	return func(p string, e peek.Entry) bool {
+		if slices.Contains(names, e.Name) {
+			return true
+		}
+		abs, err := filepath.Abs(p)
+		return err == nil && slices.Contains(paths, abs)
+	}
+}
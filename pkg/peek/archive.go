@@ -0,0 +1,178 @@
+package peek
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var archiveSuffixes = []string{".zip", ".jar", ".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2"}
+
+// IsArchive reports whether name looks like an archive peek can list.
+func IsArchive(name string) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// member is one file or dir stored in an archive.
+type member struct {
+	name    string // slash-separated, no leading or trailing slash
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
+	target  string // symlinks only
+}
+
+// InArchive reports whether p is an archive or a path inside one,
+// which Scanner lists from memory rather than the disk.
+func InArchive(p string) bool {
+	_, _, ok := splitArchive(p)
+	return ok
+}
+
+// splitArchive finds an archive file at or above p, returning its path
+// and the slash-separated location inside it. ok is false when p is an
+// ordinary path.
+func splitArchive(p string) (archive, inner string, ok bool) {
+	cur := p
+	for {
+		info, err := os.Stat(cur)
+		if err == nil {
+			if !info.Mode().IsRegular() || !IsArchive(cur) {
+				return "", "", false
+			}
+			rel, err := filepath.Rel(cur, p)
+			if err != nil || rel == "." {
+				rel = ""
+			}
+			return cur, filepath.ToSlash(rel), true
+		}
+		// Stat through a file reports ENOTDIR on Unix
+		if !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, syscall.ENOTDIR) {
+			return "", "", false
+		}
+		parent := filepath.Dir(cur)
+		if parent == cur {
+			return "", "", false
+		}
+		cur = parent
+	}
+}
+
+// OpenArchive reads the member list of a .zip or .tar[.gz|.bz2] file
+// into a read-only fs.FS. Only metadata is kept: opened files stat and
+// list normally but cannot be read.
+func OpenArchive(name string) (fs.FS, error) {
+	members, err := readArchive(name)
+	if err != nil {
+		return nil, err
+	}
+	return newMemFS(members), nil
+}
+
+func readArchive(name string) ([]member, error) {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".jar") {
+		return readZip(name)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch {
+	case strings.HasSuffix(lower, ".gz"), strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	case strings.HasSuffix(lower, ".bz2"), strings.HasSuffix(lower, ".tbz2"):
+		r = bzip2.NewReader(f)
+	}
+	return readTar(r)
+}
+
+func readZip(name string) ([]member, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var members []member
+	for _, f := range zr.File {
+		m := member{
+			name:    cleanMember(f.Name),
+			mode:    f.Mode(),
+			size:    int64(f.UncompressedSize64),
+			modTime: f.Modified,
+		}
+		if strings.HasSuffix(f.Name, "/") {
+			m.mode |= fs.ModeDir
+		}
+		if m.mode&fs.ModeSymlink != 0 {
+			// Zip stores the link target as the member body
+			if rc, err := f.Open(); err == nil {
+				b, _ := io.ReadAll(io.LimitReader(rc, 4096))
+				rc.Close()
+				m.target = string(b)
+			}
+		}
+		if m.name != "" {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func readTar(r io.Reader) ([]member, error) {
+	tr := tar.NewReader(r)
+	var members []member
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return members, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		m := member{
+			name:    cleanMember(h.Name),
+			mode:    h.FileInfo().Mode(),
+			size:    h.Size,
+			modTime: h.ModTime,
+		}
+		if m.mode&fs.ModeSymlink != 0 {
+			m.target = h.Linkname
+		}
+		if m.name != "" {
+			members = append(members, m)
+		}
+	}
+}
+
+// cleanMember normalises "./a/b/" style member names to "a/b".
+func cleanMember(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
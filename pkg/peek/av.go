@@ -0,0 +1,545 @@
+package peek
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"strings"
+	"time"
+)
+
+// Limits on how much of a media file is read looking for its headers.
+const (
+	maxMoov      = 16 << 20 // an MP4 moov box, read whole
+	maxMediaScan = 16 << 20 // skipped without a Seek, or walked in an MKV
+	mp3Window    = 64 << 10 // searched for the first MP3 frame
+	oggTail      = 64 << 10 // searched for the last Ogg page
+)
+
+// mediaParsers read an audio or video container, by extension.
+var mediaParsers = map[string]func(f fs.File) (time.Duration, string, bool){
+	"mp4": mp4Info, "m4v": mp4Info, "m4a": mp4Info, "m4b": mp4Info, "mov": mp4Info, "3gp": mp4Info,
+	"mkv": mkvInfo, "mka": mkvInfo, "webm": mkvInfo,
+	"mp3":  mp3Info,
+	"flac": flacInfo,
+	"wav":  wavInfo,
+	"ogg":  oggInfo, "oga": oggInfo, "opus": oggInfo,
+}
+
+// mediaInfo reads how long the audio or video file name plays and its
+// codec, the video one when there is video, from the container's
+// headers. ok is false for other files and headers it can't follow.
+func mediaInfo(fsys fs.FS, name, ext string) (d time.Duration, codec string, ok bool) {
+	parse := mediaParsers[ext]
+	if parse == nil {
+		return 0, "", false
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return 0, "", false
+	}
+	defer f.Close()
+	return parse(f)
+}
+
+// skip moves r on n bytes, seeking when it can.
+func skip(r io.Reader, n int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		if _, err := s.Seek(n, io.SeekCurrent); err == nil {
+			return nil
+		}
+	}
+	if n > maxMediaScan {
+		return io.ErrUnexpectedEOF
+	}
+	_, err := io.CopyN(io.Discard, r, n)
+	return err
+}
+
+// seconds turns n samples at rate per second into a duration.
+func seconds(n, rate uint64) time.Duration {
+	if rate == 0 {
+		return 0
+	}
+	return time.Duration(float64(n) / float64(rate) * float64(time.Second))
+}
+
+// mp4Info walks the top-level boxes of an ISO media file (MP4, MOV,
+// M4A) to the moov box, which holds the movie header and the tracks.
+func mp4Info(f fs.File) (time.Duration, string, bool) {
+	var hdr [16]byte
+	for {
+		if _, err := io.ReadFull(f, hdr[:8]); err != nil {
+			return 0, "", false
+		}
+		size, typ, n := int64(binary.BigEndian.Uint32(hdr[:])), string(hdr[4:8]), int64(8)
+		if size == 1 {
+			if _, err := io.ReadFull(f, hdr[8:16]); err != nil {
+				return 0, "", false
+			}
+			size, n = int64(binary.BigEndian.Uint64(hdr[8:])), 16
+		}
+		if size == 0 || size < n {
+			// Runs to the end of the file, or broken
+			return 0, "", false
+		}
+		if typ != "moov" {
+			if err := skip(f, size-n); err != nil {
+				return 0, "", false
+			}
+			continue
+		}
+		if size-n > maxMoov {
+			return 0, "", false
+		}
+		moov := make([]byte, size-n)
+		if _, err := io.ReadFull(f, moov); err != nil {
+			return 0, "", false
+		}
+		var m moovInfo
+		m.walk(moov)
+		return m.duration, firstOf(m.video, m.audio), m.duration > 0
+	}
+}
+
+// moovInfo collects what mp4Info wants from a moov box.
+type moovInfo struct {
+	duration     time.Duration
+	handler      string // "vide" or "soun" for the track being walked
+	video, audio string
+}
+
+func (m *moovInfo) walk(b []byte) {
+	for len(b) >= 8 {
+		size, typ, n := uint64(binary.BigEndian.Uint32(b)), string(b[4:8]), uint64(8)
+		if size == 1 && len(b) >= 16 {
+			size, n = binary.BigEndian.Uint64(b[8:]), 16
+		}
+		if size == 0 {
+			size = uint64(len(b))
+		}
+		if size < n || size > uint64(len(b)) {
+			return
+		}
+		body := b[n:size]
+		switch typ {
+		case "trak":
+			m.handler = ""
+			m.walk(body)
+		case "mdia", "minf", "stbl":
+			m.walk(body)
+		case "mvhd":
+			// Version 1 widens the times and the duration to 64 bits
+			switch {
+			case len(body) >= 20 && body[0] == 0:
+				m.duration = seconds(uint64(binary.BigEndian.Uint32(body[16:])), uint64(binary.BigEndian.Uint32(body[12:])))
+			case len(body) >= 32 && body[0] == 1:
+				m.duration = seconds(binary.BigEndian.Uint64(body[24:]), uint64(binary.BigEndian.Uint32(body[20:])))
+			}
+		case "hdlr":
+			if len(body) >= 12 {
+				m.handler = string(body[8:12])
+			}
+		case "stsd":
+			// The first sample entry's type is the codec
+			if len(body) < 16 {
+				break
+			}
+			codec := mp4Codec(string(body[12:16]))
+			switch {
+			case m.handler == "vide" && m.video == "":
+				m.video = codec
+			case m.handler == "soun" && m.audio == "":
+				m.audio = codec
+			}
+		}
+		b = b[size:]
+	}
+}
+
+var mp4Codecs = map[string]string{
+	"avc1": "H.264", "avc3": "H.264", "hvc1": "HEVC", "hev1": "HEVC", "av01": "AV1",
+	"vp09": "VP9", "vp08": "VP8", "mp4v": "MPEG-4", "apch": "ProRes", "apcn": "ProRes",
+	"apcs": "ProRes", "apco": "ProRes", "ap4h": "ProRes", "jpeg": "MJPEG",
+	"mp4a": "AAC", "ac-3": "AC-3", "ec-3": "E-AC-3", "Opus": "Opus", "fLaC": "FLAC",
+	"alac": "ALAC", ".mp3": "MP3", "sowt": "PCM", "twos": "PCM", "lpcm": "PCM",
+}
+
+func mp4Codec(fourcc string) string {
+	if c, ok := mp4Codecs[fourcc]; ok {
+		return c
+	}
+	return strings.TrimSpace(fourcc)
+}
+
+// firstOf is the first of a and b that isn't "".
+func firstOf(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// EBML element IDs of a Matroska or WebM file, marker bits included.
+const (
+	ebmlHeader    = 0x1A45DFA3
+	mkvSegment    = 0x18538067
+	mkvSegInfo    = 0x1549A966
+	mkvTimescale  = 0x2AD7B1
+	mkvDuration   = 0x4489
+	mkvTracks     = 0x1654AE6B
+	mkvTrackEntry = 0xAE
+	mkvTrackType  = 0x83
+	mkvCodecID    = 0x86
+	mkvCluster    = 0x1F43B675
+)
+
+// mkvInfo reads a Matroska or WebM file's segment info and tracks,
+// which come before the first cluster of frames.
+func mkvInfo(f fs.File) (time.Duration, string, bool) {
+	r := bufio.NewReader(io.LimitReader(f, maxMediaScan))
+	id, size, err := ebmlElement(r)
+	if err != nil || id != ebmlHeader || size < 0 {
+		return 0, "", false
+	}
+	if _, err := r.Discard(int(size)); err != nil {
+		return 0, "", false
+	}
+	var (
+		scale        uint64 = 1_000_000 // ns per tick
+		ticks        float64
+		trackType    uint64
+		trackCodec   string
+		video, audio string
+	)
+	endTrack := func() {
+		switch {
+		case trackType == 1 && video == "":
+			video = mkvCodec(trackCodec)
+		case trackType == 2 && audio == "":
+			audio = mkvCodec(trackCodec)
+		}
+		trackType, trackCodec = 0, ""
+	}
+loop:
+	for {
+		id, size, err := ebmlElement(r)
+		if err != nil {
+			break
+		}
+		switch id {
+		case mkvSegment, mkvSegInfo, mkvTracks:
+			// Step into the children
+		case mkvTrackEntry:
+			endTrack()
+		case mkvTimescale, mkvDuration, mkvTrackType, mkvCodecID:
+			if size < 0 || size > 1<<10 {
+				break loop
+			}
+			b := make([]byte, size)
+			if _, err := io.ReadFull(r, b); err != nil {
+				break loop
+			}
+			switch id {
+			case mkvTimescale:
+				scale = beUint(b)
+			case mkvDuration:
+				switch len(b) {
+				case 4:
+					ticks = float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+				case 8:
+					ticks = math.Float64frombits(binary.BigEndian.Uint64(b))
+				}
+			case mkvTrackType:
+				trackType = beUint(b)
+			case mkvCodecID:
+				trackCodec = string(bytes.TrimRight(b, "\x00"))
+			}
+		case mkvCluster:
+			// Frames from here on
+			break loop
+		default:
+			if size < 0 {
+				break loop
+			}
+			if _, err := r.Discard(int(size)); err != nil {
+				break loop
+			}
+		}
+	}
+	endTrack()
+	d := time.Duration(ticks * float64(scale))
+	return d, firstOf(video, audio), d > 0
+}
+
+// ebmlElement reads an element's ID and the size of its body, -1 when
+// the size is unknown.
+func ebmlElement(r *bufio.Reader) (id uint64, size int64, err error) {
+	id, _, err = ebmlVint(r, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, unknown, err := ebmlVint(r, false)
+	if unknown {
+		return id, -1, err
+	}
+	return id, int64(n), err
+}
+
+// ebmlVint reads a variable-length integer: the count of leading zero
+// bits in the first byte is the number of bytes that follow. IDs keep
+// the length marker; sizes drop it, and all ones means unknown.
+func ebmlVint(r *bufio.Reader, keepMarker bool) (v uint64, unknown bool, err error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, false, err
+	}
+	n := 0
+	for n < 8 && b&(0x80>>n) == 0 {
+		n++
+	}
+	if n == 8 {
+		return 0, false, io.ErrUnexpectedEOF
+	}
+	v = uint64(b)
+	if !keepMarker {
+		v &= uint64(0xff >> (n + 1))
+	}
+	ones := v == uint64(0xff>>(n+1))
+	for range n {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, false, err
+		}
+		v = v<<8 | uint64(c)
+		ones = ones && c == 0xff
+	}
+	return v, ones && !keepMarker, nil
+}
+
+// beUint reads a big-endian unsigned integer of up to 8 bytes.
+func beUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+var mkvCodecs = map[string]string{
+	"V_MPEG4/ISO/AVC": "H.264", "V_MPEGH/ISO/HEVC": "HEVC", "V_AV1": "AV1", "V_VP9": "VP9",
+	"V_VP8": "VP8", "V_MPEG4/ISO/ASP": "MPEG-4", "V_MPEG2": "MPEG-2", "V_THEORA": "Theora",
+	"A_AAC": "AAC", "A_OPUS": "Opus", "A_VORBIS": "Vorbis", "A_FLAC": "FLAC", "A_AC3": "AC-3",
+	"A_EAC3": "E-AC-3", "A_DTS": "DTS", "A_MPEG/L3": "MP3", "A_PCM/INT/LIT": "PCM",
+}
+
+func mkvCodec(id string) string {
+	if c, ok := mkvCodecs[id]; ok {
+		return c
+	}
+	if strings.HasPrefix(id, "A_AAC") {
+		return "AAC"
+	}
+	id = strings.TrimPrefix(strings.TrimPrefix(id, "V_"), "A_")
+	return id
+}
+
+// Layer III bitrates in kbit/s by index, for MPEG-1 and for MPEG-2 and
+// 2.5; index 0 is "free" and 15 is invalid.
+var mp3Bitrates = [2][15]int{
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+// mp3Info finds the first frame after any ID3v2 tag. A Xing, Info or
+// VBRI header there gives the frame count of a VBR file; otherwise the
+// size is divided by the bitrate, as for constant-bitrate files.
+func mp3Info(f fs.File) (time.Duration, string, bool) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, "", false
+	}
+	var start int64
+	var tag [10]byte
+	if _, err := io.ReadFull(f, tag[:]); err != nil {
+		return 0, "", false
+	}
+	head := tag[:]
+	if string(tag[:3]) == "ID3" {
+		// The tag's size is syncsafe: 7 bits per byte
+		n := int64(tag[6])<<21 | int64(tag[7])<<14 | int64(tag[8])<<7 | int64(tag[9])
+		if tag[5]&0x10 != 0 {
+			n += 10 // footer
+		}
+		if err := skip(f, n); err != nil {
+			return 0, "", false
+		}
+		start, head = 10+n, nil
+	}
+	buf := make([]byte, mp3Window)
+	copy(buf, head)
+	n, _ := io.ReadFull(f, buf[len(head):])
+	buf = buf[:len(head)+n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xff || buf[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		version, layer := buf[i+1]>>3&3, buf[i+1]>>1&3
+		rateIdx, srIdx := int(buf[i+2]>>4), buf[i+2]>>2&3
+		if version == 1 || layer != 1 || rateIdx == 0 || rateIdx == 15 || srIdx == 3 {
+			continue
+		}
+		mpeg1 := version == 3
+		sampleRate := uint64([3]int{44100, 48000, 32000}[srIdx])
+		mono := buf[i+3]>>6 == 3
+		perFrame, side, table := uint64(1152), 32, 0
+		if mono {
+			side = 17
+		}
+		if !mpeg1 {
+			sampleRate /= 2
+			if version == 0 {
+				sampleRate /= 2 // MPEG-2.5
+			}
+			perFrame, side, table = 576, 17, 1
+			if mono {
+				side = 9
+			}
+		}
+		frame := buf[i:]
+		if x := 4 + side; len(frame) >= x+12 && (string(frame[x:x+4]) == "Xing" || string(frame[x:x+4]) == "Info") {
+			if binary.BigEndian.Uint32(frame[x+4:])&1 != 0 {
+				frames := uint64(binary.BigEndian.Uint32(frame[x+8:]))
+				return seconds(frames*perFrame, sampleRate), "MP3", true
+			}
+		}
+		if len(frame) >= 36+18 && string(frame[36:40]) == "VBRI" {
+			frames := uint64(binary.BigEndian.Uint32(frame[36+14:]))
+			return seconds(frames*perFrame, sampleRate), "MP3", true
+		}
+		bits := uint64(info.Size()-start-int64(i)) * 8
+		return seconds(bits, uint64(mp3Bitrates[table][rateIdx])*1000), "MP3", true
+	}
+	return 0, "", false
+}
+
+// flacInfo reads the sample rate and count from STREAMINFO, which a
+// FLAC file must start with.
+func flacInfo(f fs.File) (time.Duration, string, bool) {
+	var b [4 + 4 + 18]byte
+	if _, err := io.ReadFull(f, b[:]); err != nil || string(b[:4]) != "fLaC" || b[4]&0x7f != 0 {
+		return 0, "", false
+	}
+	si := b[8:]
+	rate := uint64(si[10])<<12 | uint64(si[11])<<4 | uint64(si[12])>>4
+	samples := uint64(si[13]&0x0f)<<32 | uint64(binary.BigEndian.Uint32(si[14:]))
+	return seconds(samples, rate), "FLAC", rate > 0 && samples > 0
+}
+
+// wavInfo divides a WAV file's data chunk by the byte rate from its fmt
+// chunk.
+func wavInfo(f fs.File) (time.Duration, string, bool) {
+	var hdr [12]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil || string(hdr[:4]) != "RIFF" || string(hdr[8:]) != "WAVE" {
+		return 0, "", false
+	}
+	var byteRate uint64
+	codec := "WAV"
+	for {
+		var ch [8]byte
+		if _, err := io.ReadFull(f, ch[:]); err != nil {
+			return 0, "", false
+		}
+		size := int64(binary.LittleEndian.Uint32(ch[4:]))
+		switch string(ch[:4]) {
+		case "fmt ":
+			if size < 16 || size > 1<<10 {
+				return 0, "", false
+			}
+			b := make([]byte, size)
+			if _, err := io.ReadFull(f, b); err != nil {
+				return 0, "", false
+			}
+			switch binary.LittleEndian.Uint16(b) {
+			case 1, 3, 0xfffe:
+				codec = "PCM"
+			case 0x55:
+				codec = "MP3"
+			}
+			byteRate = uint64(binary.LittleEndian.Uint32(b[8:]))
+			size = 0
+		case "data":
+			return seconds(uint64(size), byteRate), codec, byteRate > 0
+		}
+		// Chunks are padded to an even length
+		if err := skip(f, size+size&1); err != nil {
+			return 0, "", false
+		}
+	}
+}
+
+// oggInfo takes the sample rate from the first page's Vorbis or Opus
+// header and the sample count from the granule position of the last
+// page, near the end of the file.
+func oggInfo(f fs.File) (time.Duration, string, bool) {
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		return 0, "", false
+	}
+	var b [27 + 255 + 19]byte
+	if _, err := io.ReadFull(rs, b[:27]); err != nil || string(b[:4]) != "OggS" {
+		return 0, "", false
+	}
+	segs := int(b[26])
+	if _, err := io.ReadFull(rs, b[27:27+segs+19]); err != nil {
+		return 0, "", false
+	}
+	pkt := b[27+segs:]
+	var rate, preSkip uint64
+	var codec string
+	switch {
+	case string(pkt[:7]) == "\x01vorbis":
+		rate, codec = uint64(binary.LittleEndian.Uint32(pkt[12:])), "Vorbis"
+	case string(pkt[:8]) == "OpusHead":
+		rate, codec = 48000, "Opus"
+		preSkip = uint64(binary.LittleEndian.Uint16(pkt[10:]))
+	default:
+		return 0, "", false
+	}
+
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, "", false
+	}
+	from := max(end-oggTail, 0)
+	if _, err := rs.Seek(from, io.SeekStart); err != nil {
+		return 0, "", false
+	}
+	tail := make([]byte, end-from)
+	if _, err := io.ReadFull(rs, tail); err != nil {
+		return 0, "", false
+	}
+	i := bytes.LastIndex(tail, []byte("OggS"))
+	if i < 0 || len(tail) < i+14 {
+		return 0, "", false
+	}
+	granule := binary.LittleEndian.Uint64(tail[i+6:])
+	if granule < preSkip {
+		return 0, "", false
+	}
+	return seconds(granule-preSkip, rate), codec, true
+}
+
+// playTime writes d as 3:07 or 1:02:03.
+func playTime(d time.Duration) string {
+	s := int64(d.Round(time.Second) / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,171 @@
+package peek
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Usage is the disk usage below a dir, broken down by subdir.
+type Usage struct {
+	Dirs  []Entry // named by their path below the dir, largest first
+	Total int64   // every file below the dir, its own included
+}
+
+// Big walks dir recursively and sizes its subdirs down to depth levels;
+// 1 is the immediate ones, 0 all of them. The dirs at the top are
+// walked concurrently, Workers at a time. ShowAll applies as in Scan;
+// symlinks are not followed.
+func (s *Scanner) Big(dir string, depth int) (Usage, error) {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return Usage{}, err
+	}
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+			pe.Path = dir
+		}
+		return Usage{}, err
+	}
+
+	var u Usage
+	var subdirs []string
+	for _, d := range entries {
+		p := path.Join(root, d.Name())
+		switch {
+		case !s.ShowAll && isHidden(d):
+		case d.IsDir():
+			if !linkedDir(fsys, p, d) {
+				subdirs = append(subdirs, p)
+			}
+		case d.Type().IsRegular():
+			if info, err := d.Info(); err == nil {
+				u.Total += info.Size()
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	s.parallel(len(subdirs), func(i int) {
+		sizes := map[string]*Entry{}
+		var total int64
+		fs.WalkDir(fsys, subdirs[i], func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			s.Progress.add(1)
+			if p != subdirs[i] && (!s.ShowAll && isHidden(d) || linkedDir(fsys, p, d)) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			rel := p
+			if root != "." {
+				rel = strings.TrimPrefix(p, root+"/")
+			}
+			if d.IsDir() {
+				if depth == 0 || strings.Count(rel, "/") < depth {
+					sizes[rel] = &Entry{Name: rel, IsDir: true, DirSized: true, Hidden: isHidden(d)}
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			total += info.Size()
+			// Count the file in each dir above it that is listed
+			for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+				if e := sizes[dir]; e != nil {
+					e.DirSize += info.Size()
+				}
+			}
+			return nil
+		})
+		mu.Lock()
+		for _, e := range sizes {
+			u.Dirs = append(u.Dirs, *e)
+		}
+		u.Total += total
+		mu.Unlock()
+	})
+
+	sort.Slice(u.Dirs, func(i, j int) bool {
+		a, b := u.Dirs[i], u.Dirs[j]
+		if a.DirSize != b.DirSize {
+			return a.DirSize > b.DirSize
+		}
+		return a.Name < b.Name
+	})
+	return u, nil
+}
+
+// UsageRenderer draws a Usage as dirs with size bars inside a box.
+type UsageRenderer struct {
+	Width int    // terminal columns; 80 when zero
+	Title string // shown above the dirs, usually the root path
+}
+
+const usageBarWidth = 20
+
+func (r UsageRenderer) Render(w io.Writer, u Usage) error {
+	footer := Plural(len(u.Dirs), "dir") + "  ·  " + HumanSize(u.Total) + " in all"
+	_, err := fmt.Fprintf(w, "\n%s\n\n  %s\n\n", r.Box(u, -1), CountStyle.Render(footer))
+	return err
+}
+
+// Box is the boxed list of u's dirs, each with its share of u.Total as a
+// bar. sel highlights one dir; -1 for none.
+func (r UsageRenderer) Box(u Usage, sel int) string {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	inner := max(width-2, 20)
+	lineWidth := min(inner-4, maxNameLen)
+
+	// size, bar and share after the dot leader
+	const tailW = 7 + 2 + usageBarWidth + 7
+	var lines []string
+	for i, d := range u.Dirs {
+		pct := 0.0
+		if u.Total > 0 {
+			pct = float64(d.DirSize) * 100 / float64(u.Total)
+		}
+		filled := min(int(pct/100*usageBarWidth+0.5), usageBarWidth)
+		bar := TitleStyle.Render(strings.Repeat("━", filled)) + sepStyle.Render(strings.Repeat("─", usageBarWidth-filled))
+
+		name := Truncate(d.Name, max(lineWidth-tailW-5, 8))
+		styled := dirNameStyle.Render(name)
+		switch {
+		case i == sel:
+			styled = cursorStyle.Render(name)
+		case d.Hidden:
+			styled = dotDirStyle.Render(name)
+		}
+		size := fmt.Sprintf("%7s", HumanSize(d.DirSize))
+		dots := max(lineWidth-2-textWidth(name)-tailW, 3)
+		lines = append(lines, dirIndicator.Render("▸")+" "+styled+" "+dotLeaderStyle.Render(strings.Repeat("·", dots-2))+" "+
+			metaStyle.Render(size)+"  "+bar+metaStyle.Render(fmt.Sprintf(" %5.1f%%", pct)))
+	}
+	if len(u.Dirs) == 0 {
+		lines = append(lines, CountStyle.Render("no subdirs"))
+	}
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(inner)
+	return box.Render(makeHeader(Truncate(r.Title, lineWidth), lineWidth) + strings.Join(lines, "\n"))
+}
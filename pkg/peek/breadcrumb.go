@@ -0,0 +1,38 @@
+package peek
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const crumbSep = " › "
+
+// Breadcrumb renders path p as "/ › home › me › src", its last part
+// highlighted. When wider than width, leading parts give way to "…".
+func Breadcrumb(p string, width int) string {
+	var parts []string
+	if strings.HasPrefix(p, "/") {
+		parts = append(parts, "/")
+	}
+	for _, part := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == filepath.Separator }) {
+		parts = append(parts, Sanitize(part))
+	}
+	if len(parts) == 0 {
+		return TitleStyle.Render(Sanitize(p))
+	}
+
+	// Swap leading parts for "…" until the rest fits
+	shown := parts
+	for i := 1; i < len(parts) && textWidth(strings.Join(shown, crumbSep)) > width; i++ {
+		shown = append([]string{"…"}, parts[i:]...)
+	}
+
+	var b strings.Builder
+	room := width
+	for _, part := range shown[:len(shown)-1] {
+		b.WriteString(CountStyle.Render(part) + sepStyle.Render(crumbSep))
+		room -= textWidth(part + crumbSep)
+	}
+	b.WriteString(TitleStyle.Render(Truncate(shown[len(shown)-1], room)))
+	return b.String()
+}
@@ -0,0 +1,245 @@
+package peek
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// syntaxStyle is the chroma style for highlighting; "" draws plain text.
+var syntaxStyle string
+
+// ReadHead returns up to n bytes from the start of the file name in
+// dir, and the file's size.
+func (s *Scanner) ReadHead(dir, name string, n int64) ([]byte, int64, error) {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := fsys.Open(path.Join(root, name))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.IsDir() {
+		return nil, 0, fmt.Errorf("%s is a directory", name)
+	}
+	b, err := io.ReadAll(io.LimitReader(f, n))
+	return b, info.Size(), err
+}
+
+// LooksBinary reports whether b, the start of a file, holds a NUL byte
+// or invalid UTF-8 in its first few KB, as previews judge it.
+func LooksBinary(b []byte, cut bool) bool {
+	head := b[:min(len(b), previewBytes)]
+	return bytes.IndexByte(head, 0) >= 0 || !validPrefix(head, cut || len(b) > previewBytes)
+}
+
+// CatRenderer draws a text file, syntax highlighted, in a box with its
+// line numbers. Binary files get a hexdump of their start instead.
+type CatRenderer struct {
+	Width int    // terminal columns; 80 when zero
+	Title string // shown above the text, usually the path
+	Name  string // file name, to pick the language by
+	Size  int64  // whole file size, for the summary when src is cut short
+	Dump  int    // bytes of a binary file to dump; HexBytes when zero
+
+	// Graphics draws images, one of the Graphics* modes, in at most
+	// Rows lines (24 when zero); "" dumps them like other binaries.
+	Graphics string
+	Rows     int
+}
+
+func (r CatRenderer) Render(w io.Writer, src []byte) error {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	inner := max(width-2, 20)
+	lineWidth := inner - 4
+	if LooksBinary(src, int64(len(src)) < r.Size) {
+		if r.Graphics != "" && drawImages {
+			if img, format, err := DecodeImage(src); err == nil {
+				return r.renderImage(w, img, format, inner, lineWidth)
+			}
+		}
+		return r.renderHex(w, src, inner, lineWidth)
+	}
+
+	lexer := lexers.Match(r.Name)
+	if lexer == nil {
+		lexer = lexers.Analyse(string(src))
+	}
+	lang := "text"
+	if lexer != nil {
+		lang = lexer.Config().Name
+	}
+	lines := highlight(lexer, string(src))
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+
+	gutter := len(fmt.Sprint(len(lines)))
+	room := max(lineWidth-gutter-3, 4)
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		num := CountStyle.Render(fmt.Sprintf("%*d", gutter, i+1)) + " " + sepStyle.Render("│") + " "
+		out[i] = num + ansi.Truncate(l, room, "…")
+	}
+	if len(lines) == 0 {
+		out = append(out, CountStyle.Render("empty"))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(inner)
+	body := makeHeader(Truncate(r.Title, lineWidth), lineWidth) + strings.Join(out, "\n")
+	summary := fmt.Sprintf("%d lines  ·  %s  ·  %s", len(lines), HumanSize(r.Size), lang)
+	if int64(len(src)) < r.Size {
+		summary += "  ·  first " + HumanSize(int64(len(src)))
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n\n  %s\n\n", box.Render(body), CountStyle.Render(summary))
+	return err
+}
+
+func (r CatRenderer) renderHex(w io.Writer, src []byte, inner, lineWidth int) error {
+	n := r.Dump
+	if n <= 0 {
+		n = HexBytes
+	}
+	_, kind := sniffBytes(src[:min(len(src), previewBytes)])
+	if kind == "" {
+		kind = "binary"
+	}
+	src = src[:min(len(src), n)]
+	lines := HexLines(src, lineWidth)
+	if len(lines) == 0 {
+		lines = append(lines, CountStyle.Render("empty"))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(inner)
+	body := makeHeader(Truncate(r.Title, lineWidth), lineWidth) + strings.Join(lines, "\n")
+	summary := kind + "  ·  " + HumanSize(r.Size)
+	if int64(len(src)) < r.Size {
+		summary += "  ·  first " + HumanSize(int64(len(src)))
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n\n  %s\n\n", box.Render(body), CountStyle.Render(summary))
+	return err
+}
+
+// MaxImagePixels is the most pixels DecodeImage decodes. Decoded
+// images take 4 to 8 bytes a pixel, and a small compressed file can
+// claim far more.
+const MaxImagePixels = 50_000_000
+
+// ErrImageTooLarge is DecodeImage's error for images over
+// MaxImagePixels.
+var ErrImageTooLarge = errors.New("image too large to draw")
+
+// DecodeImage decodes the image in src, after checking from its header
+// that it's no more than MaxImagePixels.
+func DecodeImage(src []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxImagePixels {
+		return nil, "", ErrImageTooLarge
+	}
+	return image.Decode(bytes.NewReader(src))
+}
+
+// renderImage draws img in the box as blocks, or for the kitty and
+// sixel protocols under a title, as a box can't measure their cells.
+func (r CatRenderer) renderImage(w io.Writer, img image.Image, format string, inner, lineWidth int) error {
+	rows := r.Rows
+	if rows <= 0 {
+		rows = 24
+	}
+	rows = max(rows-10, 4) // title, box and summary
+	b := img.Bounds()
+	summary := fmt.Sprintf("%s image  ·  %d×%d  ·  %s", strings.ToUpper(format), b.Dx(), b.Dy(), HumanSize(r.Size))
+	header := makeHeader(Truncate(r.Title, lineWidth), lineWidth)
+
+	var pic string
+	switch r.Graphics {
+	case GraphicsKitty:
+		pic = KittyImage(img, lineWidth, rows)
+	case GraphicsSixel:
+		pic = SixelImage(img, lineWidth, rows)
+	default:
+		box := lipgloss.NewStyle().
+			Border(boxBorder).
+			BorderForeground(borderColor).
+			Padding(1, 2).
+			Width(inner)
+		body := header + strings.Join(Mosaic(img, lineWidth, rows), "\n")
+		_, err := fmt.Fprintf(w, "\n%s\n\n  %s\n\n", box.Render(body), CountStyle.Render(summary))
+		return err
+	}
+	header = strings.ReplaceAll(header, "\n", "\n  ")
+	_, err := fmt.Fprintf(w, "\n  %s%s\n\n  %s\n\n", header, pic, CountStyle.Render(summary))
+	return err
+}
+
+// highlight splits src into lines styled per token by syntaxStyle.
+// Text is sanitized, and tabs widened to 4 spaces, before styling.
+func highlight(lexer chroma.Lexer, src string) []string {
+	clean := func(s string) string {
+		return Sanitize(strings.ReplaceAll(strings.TrimRight(s, "\r"), "\t", "    "))
+	}
+	if lexer == nil || syntaxStyle == "" {
+		lines := strings.Split(src, "\n")
+		for i, l := range lines {
+			lines[i] = clean(l)
+		}
+		return lines
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, src)
+	if err != nil {
+		return highlight(nil, src)
+	}
+	style := styles.Get(syntaxStyle)
+	lines := []string{""}
+	for tok := it(); tok != chroma.EOF; tok = it() {
+		st := tokenStyle(style.Get(tok.Type))
+		for i, part := range strings.Split(tok.Value, "\n") {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			if part != "" {
+				lines[len(lines)-1] += st.Render(clean(part))
+			}
+		}
+	}
+	return lines
+}
+
+// tokenStyle turns a chroma style entry into lipgloss.
+func tokenStyle(e chroma.StyleEntry) lipgloss.Style {
+	st := lipgloss.NewStyle()
+	if e.Colour.IsSet() {
+		st = st.Foreground(lipgloss.Color(e.Colour.String()))
+	}
+	return st.Bold(e.Bold == chroma.Yes).Italic(e.Italic == chroma.Yes).Underline(e.Underline == chroma.Yes)
+}
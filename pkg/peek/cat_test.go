@@ -0,0 +1,41 @@
+package peek
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// pngHeader is a PNG's signature and IHDR chunk for a w×h grayscale
+// image: all DecodeConfig reads, without the pixels.
+func pngHeader(w, h uint32) []byte {
+	ihdr := binary.BigEndian.AppendUint32([]byte("IHDR"), w)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, h)
+	ihdr = append(ihdr, 8, 0, 0, 0, 0) // 8-bit gray, no interlace
+	b := []byte("\x89PNG\r\n\x1a\n")
+	b = binary.BigEndian.AppendUint32(b, uint32(len(ihdr)-4))
+	b = append(b, ihdr...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestDecodeImagePixelBudget(t *testing.T) {
+	if _, _, err := DecodeImage(pngHeader(30000, 30000)); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("30000×30000 PNG: err = %v, want ErrImageTooLarge", err)
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, image.NewGray(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := DecodeImage(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "png" || img.Bounds().Dx() != 4 || img.Bounds().Dy() != 3 {
+		t.Errorf("got %s %v, want a 4×3 png", format, img.Bounds())
+	}
+}
@@ -0,0 +1,105 @@
+package peek
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// cleanRule is a dir name that holds caches or build output, and the
+// files of which one must sit beside it for it to count as such; none
+// when the name alone is enough.
+type cleanRule struct {
+	name    string
+	besides []string
+}
+
+var cleanRules = []cleanRule{
+	{"node_modules", nil},
+	{"__pycache__", nil},
+	{".pytest_cache", nil},
+	{".mypy_cache", nil},
+	{".ruff_cache", nil},
+	{".tox", nil},
+	{".gradle", nil},
+	{".next", nil},
+	{".cache", nil},
+	{"target", []string{"Cargo.toml", "pom.xml"}},
+	{"build", []string{"package.json", "build.gradle", "build.gradle.kts", "CMakeLists.txt", "setup.py", "pyproject.toml"}},
+	{"dist", []string{"package.json", "setup.py", "pyproject.toml"}},
+}
+
+// Cleanable walks dir for cache and build dirs such as node_modules,
+// __pycache__ and a Cargo project's target, and returns them with their
+// recursive sizes, largest first, each named by its path below dir.
+// Dirs like build and dist only count next to a manifest that makes
+// them output. Found dirs aren't walked into, and neither is .git.
+func (s *Scanner) Cleanable(dir string) ([]Entry, error) {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	var found []Entry
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			s.Errors.add(err)
+			return nil
+		}
+		s.Progress.add(1)
+		if !d.IsDir() || p == root {
+			return nil
+		}
+		if d.Name() == ".git" || linkedDir(fsys, p, d) {
+			return fs.SkipDir
+		}
+		if cleanable(fsys, p, d.Name()) {
+			name := p
+			if root != "." {
+				name = strings.TrimPrefix(p, root+"/")
+			}
+			found = append(found, Entry{Name: name, IsDir: true, Hidden: isHidden(d)})
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+			pe.Path = dir
+		}
+		return nil, err
+	}
+
+	s.parallel(len(found), func(i int) {
+		found[i].DirSize = s.dirSize(fsys, path.Join(root, found[i].Name))
+		found[i].DirSized = true
+	})
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].DirSize != found[j].DirSize {
+			return found[i].DirSize > found[j].DirSize
+		}
+		return found[i].Name < found[j].Name
+	})
+	return found, nil
+}
+
+// cleanable reports whether the dir at p is one of cleanRules.
+func cleanable(fsys fs.FS, p, name string) bool {
+	for _, r := range cleanRules {
+		if r.name != name {
+			continue
+		}
+		if r.besides == nil {
+			return true
+		}
+		for _, f := range r.besides {
+			if _, err := fs.Stat(fsys, path.Join(path.Dir(p), f)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+package peek
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LangStat totals the source files below a dir in one language.
+type LangStat struct {
+	Lang     string
+	Files    int
+	Lines    int
+	Code     int
+	Comments int
+	Blanks   int
+}
+
+// language is how comments look in one language. Lines starting with
+// line or inside block count as comments.
+type language struct {
+	name  string
+	line  []string
+	block [2]string // start and end; empty when there are none
+}
+
+var (
+	cLike    = language{line: []string{"//"}, block: [2]string{"/*", "*/"}}
+	hashLike = language{line: []string{"#"}}
+	dashLike = language{line: []string{"--"}}
+	markup   = language{block: [2]string{"<!--", "-->"}}
+)
+
+func lang(name string, l language) language {
+	l.name = name
+	return l
+}
+
+// Languages by extension; the names of files that have none are in
+// langByName
+var langByExt = map[string]language{
+	"go":    lang("Go", cLike),
+	"c":     lang("C", cLike),
+	"h":     lang("C", cLike),
+	"cc":    lang("C++", cLike),
+	"cpp":   lang("C++", cLike),
+	"hpp":   lang("C++", cLike),
+	"cs":    lang("C#", cLike),
+	"java":  lang("Java", cLike),
+	"kt":    lang("Kotlin", cLike),
+	"swift": lang("Swift", cLike),
+	"rs":    lang("Rust", cLike),
+	"js":    lang("JavaScript", cLike),
+	"mjs":   lang("JavaScript", cLike),
+	"jsx":   lang("JavaScript", cLike),
+	"ts":    lang("TypeScript", cLike),
+	"tsx":   lang("TypeScript", cLike),
+	"css":   lang("CSS", language{block: [2]string{"/*", "*/"}}),
+	"scss":  lang("SCSS", cLike),
+	"php":   lang("PHP", language{line: []string{"//", "#"}, block: [2]string{"/*", "*/"}}),
+	"py":    lang("Python", hashLike),
+	"rb":    lang("Ruby", hashLike),
+	"pl":    lang("Perl", hashLike),
+	"sh":    lang("Shell", hashLike),
+	"bash":  lang("Shell", hashLike),
+	"zsh":   lang("Shell", hashLike),
+	"ps1":   lang("PowerShell", language{line: []string{"#"}, block: [2]string{"<#", "#>"}}),
+	"r":     lang("R", hashLike),
+	"yaml":  lang("YAML", hashLike),
+	"yml":   lang("YAML", hashLike),
+	"toml":  lang("TOML", hashLike),
+	"sql":   lang("SQL", language{line: []string{"--"}, block: [2]string{"/*", "*/"}}),
+	"lua":   lang("Lua", dashLike),
+	"hs":    lang("Haskell", language{line: []string{"--"}, block: [2]string{"{-", "-}"}}),
+	"html":  lang("HTML", markup),
+	"xml":   lang("XML", markup),
+	"svg":   lang("SVG", markup),
+	"vue":   lang("Vue", markup),
+	"md":    lang("Markdown", markup),
+	"json":  lang("JSON", language{}),
+}
+
+var langByName = map[string]language{
+	"Makefile":    lang("Makefile", hashLike),
+	"GNUmakefile": lang("Makefile", hashLike),
+	"Dockerfile":  lang("Dockerfile", hashLike),
+}
+
+// Files larger than this are skipped as generated
+const maxCodeFile = 8 << 20
+
+// Code walks dir recursively and counts the lines of its source files
+// per language, judged by extension, most code first. ShowAll applies as
+// in Scan, and dirs named in Prune are skipped.
+func (s *Scanner) Code(dir string) ([]LangStat, error) {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	type source struct {
+		path string
+		lang language
+	}
+	var files []source
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			s.Errors.add(err)
+			return nil
+		}
+		s.Progress.add(1)
+		if p != root && (!s.ShowAll && isHidden(d) || linkedDir(fsys, p, d) || d.IsDir() && slices.Contains(s.Prune, d.Name())) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		l, ok := langByName[d.Name()]
+		if !ok {
+			l, ok = langByExt[strings.ToLower(strings.TrimPrefix(path.Ext(d.Name()), "."))]
+		}
+		if ok {
+			files = append(files, source{p, l})
+		}
+		return nil
+	})
+	if err != nil {
+		if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+			pe.Path = dir
+		}
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	byLang := map[string]*LangStat{}
+	s.parallel(len(files), func(i int) {
+		st, ok := countCode(fsys, files[i].path, files[i].lang)
+		if !ok {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		total := byLang[st.Lang]
+		if total == nil {
+			total = &LangStat{Lang: st.Lang}
+			byLang[st.Lang] = total
+		}
+		total.Files++
+		total.Lines += st.Lines
+		total.Code += st.Code
+		total.Comments += st.Comments
+		total.Blanks += st.Blanks
+	})
+
+	stats := make([]LangStat, 0, len(byLang))
+	for _, st := range byLang {
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Code != stats[j].Code {
+			return stats[i].Code > stats[j].Code
+		}
+		return stats[i].Lang < stats[j].Lang
+	})
+	return stats, nil
+}
+
+// countCode sorts the lines of one file into code, comments and blanks;
+// false when it is unreadable, too large or binary.
+func countCode(fsys fs.FS, name string, l language) (LangStat, bool) {
+	st := LangStat{Lang: l.name}
+	if info, err := fs.Stat(fsys, name); err != nil || info.Size() > maxCodeFile {
+		return st, false
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil || bytes.IndexByte(data[:min(len(data), previewBytes)], 0) >= 0 {
+		return st, false
+	}
+	inBlock := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, maxCodeFile)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		st.Lines++
+		switch {
+		case inBlock:
+			st.Comments++
+			inBlock = !strings.Contains(line, l.block[1])
+		case line == "":
+			st.Blanks++
+		case startsWithAny(line, l.line):
+			st.Comments++
+		case l.block[0] != "" && strings.HasPrefix(line, l.block[0]):
+			st.Comments++
+			inBlock = !strings.Contains(line[len(l.block[0]):], l.block[1])
+		default:
+			st.Code++
+			// A block opened after code carries on to later lines
+			if l.block[0] != "" {
+				if i := strings.LastIndex(line, l.block[0]); i >= 0 {
+					inBlock = !strings.Contains(line[i+len(l.block[0]):], l.block[1])
+				}
+			}
+		}
+	}
+	return st, true
+}
+
+func startsWithAny(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// CodeRenderer draws language totals as a table inside a box.
+type CodeRenderer struct {
+	Width int    // terminal columns; 80 when zero
+	Title string // shown above the table, usually the root path
+}
+
+func (r CodeRenderer) Render(w io.Writer, stats []LangStat) error {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	inner := max(width-2, 20)
+	lineWidth := min(inner-4, maxNameLen)
+
+	var sum LangStat
+	for _, st := range stats {
+		sum.Files += st.Files
+		sum.Lines += st.Lines
+		sum.Code += st.Code
+		sum.Comments += st.Comments
+		sum.Blanks += st.Blanks
+	}
+
+	// Columns: language and a dot leader up to the numbers
+	cols := func(files, lines, code, comments, blanks string) string {
+		return fmt.Sprintf("%6s%9s%9s%10s%8s", files, lines, code, comments, blanks)
+	}
+	tailW := len(cols("", "", "", "", ""))
+	head := "LANGUAGE" + strings.Repeat(" ", max(lineWidth-tailW-8, 1)) + cols("FILES", "LINES", "CODE", "COMMENT", "BLANK")
+	lines := []string{TitleStyle.Render(head)}
+	for _, st := range stats {
+		name := Truncate(st.Lang, max(lineWidth-tailW-4, 4))
+		nums := strings.TrimLeft(cols(fmt.Sprint(st.Files), fmt.Sprint(st.Lines), fmt.Sprint(st.Code),
+			share(st.Comments, st.Lines), share(st.Blanks, st.Lines)), " ")
+		dots := max(lineWidth-textWidth(name)-len(nums), 3)
+		lines = append(lines, fileNameStyle.Render(name)+" "+dotLeaderStyle.Render(strings.Repeat("·", dots-2))+" "+metaStyle.Render(nums))
+	}
+	if len(stats) == 0 {
+		lines = append(lines, CountStyle.Render("no source files"))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(inner)
+	body := makeHeader(Truncate(r.Title, lineWidth), lineWidth) + strings.Join(lines, "\n")
+	summary := fmt.Sprintf("%s  ·  %s  ·  %d code  ·  %s comments  ·  %s blank",
+		Plural(sum.Files, "file"), Plural(sum.Lines, "line"), sum.Code, share(sum.Comments, sum.Lines), share(sum.Blanks, sum.Lines))
+	_, err := fmt.Fprintf(w, "\n%s\n\n  %s\n\n", box.Render(body), CountStyle.Render(summary))
+	return err
+}
+
+// share is n as a percentage of total, "12.5%".
+func share(n, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,81 @@
+package peek
+
+import (
+	"image"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Column draws entries, dirs then files, in one box titled title, as a
+// pane of a Miller-column view. sel highlights one entry; -1 for none.
+func (r PanelRenderer) Column(title string, entries []Entry, sel int) string {
+	width := r.contentWidth(false)
+	lineWidth := min(width, maxNameLen)
+	var lines []string
+	for i, e := range entries {
+		if e.IsDir {
+			lines = append(lines, r.dirLine(e, lineWidth, i == sel))
+		} else {
+			lines = append(lines, r.fileLines(e, lineWidth, i == sel)...)
+		}
+	}
+	if len(entries) == 0 {
+		lines = append(lines, CountStyle.Render("empty"))
+	}
+	return r.box(width).Render(makeHeader(Truncate(title, lineWidth), lineWidth) + strings.Join(lines, "\n"))
+}
+
+// TextColumn draws lines of a file, dimmed, in a box titled title; an
+// empty lines says there is nothing to preview.
+func (r PanelRenderer) TextColumn(title string, lines []string) string {
+	width := r.contentWidth(false)
+	lineWidth := min(width, maxNameLen)
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if l != "" {
+			l = Truncate(l, lineWidth)
+		}
+		out[i] = previewStyle.Render(l)
+	}
+	if len(lines) == 0 {
+		out = append(out, CountStyle.Render("no preview"))
+	}
+	return r.box(width).Render(makeHeader(Truncate(title, lineWidth), lineWidth) + strings.Join(out, "\n"))
+}
+
+// HexColumn draws the start of a binary file as a hexdump, at most rows
+// lines of it, in a box titled title.
+func (r PanelRenderer) HexColumn(title string, b []byte, rows int) string {
+	width := r.contentWidth(false)
+	lineWidth := min(width, maxNameLen)
+	lines := HexLines(b, lineWidth)
+	lines = lines[:min(len(lines), rows)]
+	for i, l := range lines {
+		lines[i] = ansi.Truncate(l, lineWidth, "…")
+	}
+	return r.box(width).Render(makeHeader(Truncate(title, lineWidth), lineWidth) + strings.Join(lines, "\n"))
+}
+
+// ImageColumn draws img as a block mosaic, at most rows lines of it,
+// in a box titled title.
+func (r PanelRenderer) ImageColumn(title string, img image.Image, rows int) string {
+	width := r.contentWidth(false)
+	lineWidth := min(width, maxNameLen)
+	lines := Mosaic(img, lineWidth, rows)
+	if len(lines) == 0 {
+		lines = append(lines, CountStyle.Render("no preview"))
+	}
+	return r.box(width).Render(makeHeader(Truncate(title, lineWidth), lineWidth) + strings.Join(lines, "\n"))
+}
+
+// box is the bordered style of a panel holding width cells of content.
+func (r PanelRenderer) box(width int) lipgloss.Style {
+	// Width() includes padding but not border; border adds 2
+	return lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(width + 4)
+}
@@ -0,0 +1,35 @@
+package peek
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// CSVRenderer writes one row per entry after a header row: name, type,
+// size, sub_dirs, sub_files and target. Child counts are empty for files.
+type CSVRenderer struct {
+	Comma rune // field separator; ',' when zero, '\t' for TSV
+}
+
+func (r CSVRenderer) Render(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if r.Comma != 0 {
+		cw.Comma = r.Comma
+	}
+	cw.Write([]string{"name", "type", "size", "sub_dirs", "sub_files", "target"})
+	for _, e := range entries {
+		typ, subDirs, subFiles := "file", "", ""
+		if e.IsDir {
+			typ = "dir"
+			subDirs, subFiles = strconv.Itoa(e.SubDirs), strconv.Itoa(e.SubFiles)
+		}
+		size := e.Size
+		if e.DirSized {
+			size = e.DirSize
+		}
+		cw.Write([]string{e.Name, typ, strconv.FormatInt(size, 10), subDirs, subFiles, e.Target})
+	}
+	cw.Flush()
+	return cw.Error()
+}
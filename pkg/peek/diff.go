@@ -0,0 +1,247 @@
+package peek
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Diff is the result of comparing two directory trees. Entry names are
+// slash-separated paths relative to the compared roots. A dir present
+// on one side only is listed once, without its contents.
+type Diff struct {
+	OnlyA, OnlyB []Entry
+	Changed      []Change
+}
+
+// Change is a path present on both sides whose type, size or mtime differ.
+type Change struct {
+	A, B Entry
+}
+
+// DiffDirs compares dir a as listed by sa with dir b as listed by sb,
+// so the two sides may live on different filesystems. ShowAll is taken
+// from each scanner; mtimes are compared to the second.
+func DiffDirs(sa *Scanner, a string, sb *Scanner, b string) (Diff, error) {
+	left, err := sa.collect(a)
+	if err != nil {
+		return Diff{}, err
+	}
+	right, err := sb.collect(b)
+	if err != nil {
+		return Diff{}, err
+	}
+
+	var d Diff
+	d.OnlyA = onlyIn(left, right)
+	d.OnlyB = onlyIn(right, left)
+	for p, ea := range left {
+		eb, ok := right[p]
+		if !ok || ea.IsDir && eb.IsDir {
+			continue
+		}
+		if ea.IsDir != eb.IsDir || ea.Size != eb.Size ||
+			!ea.ModTime.Truncate(time.Second).Equal(eb.ModTime.Truncate(time.Second)) {
+			d.Changed = append(d.Changed, Change{ea, eb})
+		}
+	}
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].A.Name < d.Changed[j].A.Name })
+	return d, nil
+}
+
+// onlyIn lists the paths of x missing from y, skipping those below a
+// dir that is itself missing.
+func onlyIn(x, y map[string]Entry) []Entry {
+	var out []Entry
+	for p, e := range x {
+		if _, ok := y[p]; ok {
+			continue
+		}
+		if parent := path.Dir(p); parent != "." {
+			if _, ok := y[parent]; !ok {
+				continue
+			}
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// collect walks dir recursively into entries keyed by relative path,
+// filling in child counts for dirs.
+func (s *Scanner) collect(dir string) (map[string]Entry, error) {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]Entry{}
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+		name := d.Name()
+		hidden := isHidden(d)
+		if hidden && !s.ShowAll {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel := relPath(root, p)
+		e := Entry{
+			Name:      rel,
+			IsDir:     d.IsDir(),
+			IsSymlink: d.Type()&fs.ModeSymlink != 0,
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+			Mode:      info.Mode(),
+			Hidden:    hidden,
+		}
+		if !e.IsDir {
+			e.Ext = strings.TrimPrefix(path.Ext(name), ".")
+		}
+		entries[rel] = e
+		if parent, ok := entries[path.Dir(rel)]; ok {
+			if e.IsDir {
+				parent.SubDirs++
+			} else {
+				parent.SubFiles++
+			}
+			entries[path.Dir(rel)] = parent
+		}
+		if linkedDir(fsys, p, d) {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+		pe.Path = dir
+	}
+	return entries, err
+}
+
+// Diff draws d as three boxes, ONLY IN a, ONLY IN b and DIFFERING,
+// side by side when the width allows and stacked otherwise, then a
+// count footer.
+func (r PanelRenderer) Diff(d Diff, a, b string) string {
+	footer := "identical"
+	if len(d.OnlyA)+len(d.OnlyB)+len(d.Changed) > 0 {
+		footer = fmt.Sprintf("%d only in %s  ·  %d only in %s  ·  %d differ", len(d.OnlyA), a, len(d.OnlyB), b, len(d.Changed))
+	}
+	return r.diffView(d, [3]string{"ONLY IN " + a, "ONLY IN " + b, "DIFFERING"}, footer)
+}
+
+// diffView lays out the three boxes of d under titles, then footer.
+func (r PanelRenderer) diffView(d Diff, titles [3]string, footer string) string {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	gap := 2
+	innerW := (width-2*gap)/3 - 2
+	stacked := innerW < 30
+	if stacked {
+		innerW = max(width-2, 20)
+	}
+	lineWidth := min(innerW-4, maxNameLen)
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(innerW)
+
+	var changed []string
+	for _, c := range d.Changed {
+		changed = append(changed, r.diffLine(c.B, changeSubtitle(c), lineWidth))
+	}
+	panels := []string{
+		box.Render(makeHeader(Truncate(titles[0], lineWidth), lineWidth) + r.diffContent(d.OnlyA, lineWidth)),
+		box.Render(makeHeader(Truncate(titles[1], lineWidth), lineWidth) + r.diffContent(d.OnlyB, lineWidth)),
+		box.Render(makeHeader(Truncate(titles[2], lineWidth), lineWidth) + orNothing(changed)),
+	}
+
+	var out string
+	if stacked {
+		out = lipgloss.JoinVertical(lipgloss.Left, panels...)
+	} else {
+		space := strings.Repeat(" ", gap)
+		out = lipgloss.JoinHorizontal(lipgloss.Top, panels[0], space, panels[1], space, panels[2])
+	}
+	return out + "\n\n  " + CountStyle.Render(footer)
+}
+
+func (r PanelRenderer) diffContent(entries []Entry, lineWidth int) string {
+	var lines []string
+	for _, e := range entries {
+		sub := HumanSize(e.Size)
+		if e.IsDir {
+			sub = subtitle(e)
+		}
+		lines = append(lines, r.diffLine(e, sub, lineWidth))
+	}
+	return orNothing(lines)
+}
+
+// diffLine renders one path with the panels' prefix, dot leader and
+// subtitle; dirs get the dir marker and style.
+func (r PanelRenderer) diffLine(e Entry, sub string, lineWidth int) string {
+	prefix, prefixW := "  ", 2
+	if e.IsDir {
+		prefix = dirIndicator.Render("▸") + " "
+	}
+	if icon := r.Icons.Icon(e); icon != "" {
+		prefix = dirIndicator.Render(icon) + " "
+		prefixW = textWidth(icon) + 1
+	}
+	name := Truncate(e.Name, max(lineWidth-textWidth(sub)-prefixW-3, 8))
+	style := fileNameStyle
+	switch {
+	case e.IsSymlink:
+		style = symNameStyle
+	case e.IsDir:
+		style = dirNameStyle
+	}
+	dots := max(lineWidth-textWidth(name)-textWidth(sub)-prefixW, 3)
+	leader := " " + dotLeaderStyle.Render(strings.Repeat("·", dots-2)) + " "
+	return prefix + style.Render(name) + leader + metaStyle.Render(sub)
+}
+
+// changeSubtitle says what differs: the type, the size, or else the mtime.
+func changeSubtitle(c Change) string {
+	switch {
+	case c.A.IsDir != c.B.IsDir:
+		if c.A.IsDir {
+			return "dir → file"
+		}
+		return "file → dir"
+	case c.A.Size != c.B.Size:
+		return HumanSize(c.A.Size) + " → " + HumanSize(c.B.Size)
+	case c.B.ModTime.After(c.A.ModTime):
+		return "newer"
+	default:
+		return "older"
+	}
+}
+
+func orNothing(lines []string) string {
+	if len(lines) == 0 {
+		return CountStyle.Render("nothing")
+	}
+	return strings.Join(lines, "\n")
+}
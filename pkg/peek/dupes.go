@@ -0,0 +1,148 @@
+package peek
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DupeGroup is a set of files below a scanned dir with identical content.
+type DupeGroup struct {
+	Size  int64
+	Paths []string // relative to the scanned dir, sorted
+}
+
+// Wasted is the space taken by all copies but one.
+func (g DupeGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+// Dupes walks dir recursively and groups files with identical content,
+// most wasted space first. Files are bucketed by size and only the
+// buckets with several members are hashed. Empty and unreadable files
+// are skipped; ShowAll and Match apply as in Scan.
+func (s *Scanner) Dupes(dir string) ([]DupeGroup, error) {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	bySize := map[int64][]string{}
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		if p != root && !s.ShowAll && isHidden(d) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if p != root && linkedDir(fsys, p, d) {
+			return fs.SkipDir
+		}
+		if !d.Type().IsRegular() || !s.matches(d.Name()) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Size() > 0 {
+			bySize[info.Size()] = append(bySize[info.Size()], p)
+		}
+		return nil
+	})
+	if err != nil {
+		if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+			pe.Path = dir
+		}
+		return nil, err
+	}
+
+	var groups []DupeGroup
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+		byHash := map[uint64][]string{}
+		for _, p := range paths {
+			if sum, ok := hashFile(fsys, p); ok {
+				byHash[sum] = append(byHash[sum], relPath(root, p))
+			}
+		}
+		for _, same := range byHash {
+			if len(same) > 1 {
+				sort.Strings(same)
+				groups = append(groups, DupeGroup{Size: size, Paths: same})
+			}
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if wi, wj := groups[i].Wasted(), groups[j].Wasted(); wi != wj {
+			return wi > wj
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return groups, nil
+}
+
+func hashFile(fsys fs.FS, name string) (uint64, bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	h := xxhash.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return 0, false
+	}
+	return h.Sum64(), true
+}
+
+func relPath(root, p string) string {
+	if root == "." {
+		return p
+	}
+	return strings.TrimPrefix(p, root+"/")
+}
+
+// Dupes draws groups as a wide DUPES box: each group's size and copy
+// count over its paths, then the total wasted space.
+func (r PanelRenderer) Dupes(groups []DupeGroup) string {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	inner := max(width-2, 20)
+	lineWidth := min(inner-4, maxNameLen)
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(inner)
+
+	if len(groups) == 0 {
+		return box.Render(makeHeader("DUPES", lineWidth) + CountStyle.Render("no duplicates"))
+	}
+
+	var lines []string
+	var wasted int64
+	for _, g := range groups {
+		wasted += g.Wasted()
+		head := fmt.Sprintf("%d × %s", len(g.Paths), HumanSize(g.Size))
+		sub := HumanSize(g.Wasted()) + " wasted"
+		dots := max(lineWidth-textWidth(head)-textWidth(sub)-2, 3)
+		lines = append(lines, "  "+fileNameStyle.Render(head)+" "+dotLeaderStyle.Render(strings.Repeat("·", dots-2))+" "+metaStyle.Render(sub))
+		for _, p := range g.Paths {
+			lines = append(lines, "    "+metaStyle.Render(Truncate(p, lineWidth-4)))
+		}
+	}
+	summary := HumanSize(wasted) + " wasted in " + Plural(len(groups), "group")
+	lines = append(lines, "", CountStyle.Render(summary))
+	return box.Render(makeHeader("DUPES", lineWidth) + strings.Join(lines, "\n"))
+}
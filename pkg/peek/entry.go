@@ -0,0 +1,82 @@
+// Package peek scans a directory and renders it as side-by-side
+// DIRS/FILES lipgloss panels.
+package peek
+
+import (
+	"io/fs"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Entry is one item of a directory listing.
+type Entry struct {
+	Name      string
+	IsDir     bool // true for symlinks that resolve to a directory
+	IsSymlink bool
+	Target    string // link destination, symlinks only; see Scanner.Scan
+	Size      int64
+	ModTime   time.Time
+	Mode      fs.FileMode
+	Owner     string   // user name, set when Scanner.Owners
+	Group     string   // group name, set when Scanner.Owners
+	Preview   []string // leading lines of text files, set when Scanner.Preview
+	Lines     int      // lines in a text file, set when Scanner.Lines
+	Counted   bool     // Lines was counted: the file looks like text
+	Binary    bool     // a NUL byte near the start, set when Scanner.Sniff
+	Detected  string   // kind named by the content, e.g. "PNG image", when sniffed
+	Sniffed   bool     // Binary and Detected were checked
+	Width     int      // image pixels across, set when Scanner.Dimensions
+	Height    int      // image pixels down
+	Hidden    bool     // dotfile, or hidden or system attribute on Windows
+	Ignored   bool     // matched by .gitignore rules, set when Scanner.MarkIgnored
+	Pruned    bool     // named in Scanner.Prune: neither counted nor walked into
+	Ext       string   // without the leading dot; empty for dirs
+	SubDirs   int      // immediate child dirs, dirs only
+	SubFiles  int      // immediate child files, dirs only
+	SubCapped bool     // counting stopped at Scanner.MaxChildren
+	About     string   // the dir's own description, from its .peek or .dirinfo file
+	DirSize   int64    // recursive size, dirs only
+	DirSized  bool     // DirSize was computed (Scanner.DiskUsage)
+	Note      string   // extra subtitle ahead of the size, e.g. a verify status
+	Flagged   bool     // drawn in the error color
+	Pinned    bool     // listed first in its panel, marked; see Scanner.Pinned
+
+	// Unavailable is set when reading the entry timed out (see
+	// Scanner.Timeout): only its name and type are known.
+	Unavailable bool
+
+	// Read from media files when Scanner.Media is set.
+	Duration time.Duration // play time of audio and video
+	Codec    string        // e.g. "H.264", the video one when there is video
+	Taken    time.Time     // when a photo was shot, from its EXIF data
+	Pages    int           // of a PDF
+}
+
+// Executable reports whether e is a file that can be run: one with an
+// execute bit set, or on Windows one with an .exe, .com, .bat or .cmd
+// extension.
+func (e Entry) Executable() bool {
+	if e.IsDir || !e.Mode.IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(e.Ext) {
+		case "exe", "com", "bat", "cmd":
+			return true
+		}
+	}
+	return e.Mode&0o111 != 0
+}
+
+// Split separates a listing into its dirs and files, keeping order.
+func Split(entries []Entry) (dirs, files []Entry) {
+	for _, e := range entries {
+		if e.IsDir {
+			dirs = append(dirs, e)
+		} else {
+			files = append(files, e)
+		}
+	}
+	return dirs, files
+}
@@ -0,0 +1,339 @@
+package peek
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// Limits on what is read looking for a photo's EXIF block.
+const (
+	maxExif     = 1 << 20   // an EXIF block, or a HEIC meta box
+	tiffPrefix  = 256 << 10 // read of a TIFF-based raw file
+	maxSegments = 32        // JPEG segments passed before giving up
+)
+
+// EXIF tags on the way to the capture time.
+const (
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+)
+
+// photoTaken reads when the photo name was shot, from the
+// DateTimeOriginal in its EXIF data: JPEG, HEIC and TIFF-based raw
+// files. The time is in the offset EXIF records alongside it, or local
+// time when there is none.
+func photoTaken(fsys fs.FS, name, ext string) (time.Time, bool) {
+	var read func(fs.File) ([]byte, bool)
+	switch ext {
+	case "jpg", "jpeg":
+		read = jpegExif
+	case "heic", "heif":
+		read = heicExif
+	case "tif", "tiff", "dng", "nef", "cr2", "arw":
+		read = func(f fs.File) ([]byte, bool) {
+			b := make([]byte, tiffPrefix)
+			n, _ := io.ReadFull(f, b)
+			return b[:n], n > 0
+		}
+	default:
+		return time.Time{}, false
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+	tiff, ok := read(f)
+	if !ok {
+		return time.Time{}, false
+	}
+	return exifTaken(tiff)
+}
+
+// jpegExif returns the TIFF data of a JPEG's APP1 Exif segment, which
+// comes before the image data.
+func jpegExif(f fs.File) ([]byte, bool) {
+	r := bufio.NewReader(f)
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xff, 0xd8} {
+		return nil, false
+	}
+	for range maxSegments {
+		var hdr [4]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil || hdr[0] != 0xff {
+			return nil, false
+		}
+		marker, size := hdr[1], int(binary.BigEndian.Uint16(hdr[2:]))-2
+		if marker == 0xda || marker == 0xd9 || size < 0 {
+			// Start of scan or end of image: no EXIF
+			return nil, false
+		}
+		if marker != 0xe1 {
+			if _, err := r.Discard(size); err != nil {
+				return nil, false
+			}
+			continue
+		}
+		b := make([]byte, size)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, false
+		}
+		if tiff, ok := strings.CutPrefix(string(b), "Exif\x00\x00"); ok {
+			return []byte(tiff), true
+		}
+	}
+	return nil, false
+}
+
+// heicExif finds the Exif item of a HEIF file through the meta box:
+// iinf names each item's type, iloc says where its bytes are.
+func heicExif(f fs.File) ([]byte, bool) {
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		return nil, false
+	}
+	var hdr [8]byte
+	var meta []byte
+	for meta == nil {
+		if _, err := io.ReadFull(rs, hdr[:]); err != nil {
+			return nil, false
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:]))
+		if size < 8 {
+			return nil, false
+		}
+		if string(hdr[4:]) != "meta" {
+			if _, err := rs.Seek(size-8, io.SeekCurrent); err != nil {
+				return nil, false
+			}
+			continue
+		}
+		if size-8 > maxExif || size < 12 {
+			return nil, false
+		}
+		meta = make([]byte, size-8)
+		if _, err := io.ReadFull(rs, meta); err != nil {
+			return nil, false
+		}
+	}
+
+	// meta is a full box: version and flags come first
+	var id uint32
+	var locs []byte
+	for b := meta[4:]; len(b) >= 8; {
+		size := int(binary.BigEndian.Uint32(b))
+		if size < 8 || size > len(b) {
+			break
+		}
+		switch string(b[4:8]) {
+		case "iinf":
+			id = exifItem(b[8:size])
+		case "iloc":
+			locs = b[8:size]
+		}
+		b = b[size:]
+	}
+	if id == 0 || locs == nil {
+		return nil, false
+	}
+	off, n, ok := itemExtent(locs, id)
+	if !ok || n < 8 || n > maxExif {
+		return nil, false
+	}
+	if _, err := rs.Seek(int64(off), io.SeekStart); err != nil {
+		return nil, false
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rs, b); err != nil {
+		return nil, false
+	}
+	// The item starts with the offset of the TIFF header past these 4 bytes
+	skip := 4 + uint64(binary.BigEndian.Uint32(b))
+	if skip >= n {
+		return nil, false
+	}
+	return b[skip:], true
+}
+
+// exifItem returns the ID of the item of type "Exif" in an iinf box's
+// body, 0 when there is none.
+func exifItem(b []byte) uint32 {
+	// Version and flags, then an entry count: 16 bits in version 0
+	skip := 6
+	if len(b) > 0 && b[0] > 0 {
+		skip = 8
+	}
+	if len(b) < skip {
+		return 0
+	}
+	b = b[skip:]
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b))
+		if size < 8 || size > len(b) {
+			return 0
+		}
+		infe := b[8:size]
+		b = b[size:]
+		if len(infe) < 4 || infe[0] < 2 {
+			continue
+		}
+		// Version 2 has a 16-bit item ID, 3 a 32-bit one; a protection
+		// index follows, then the item type
+		var id uint32
+		var rest []byte
+		if infe[0] == 2 && len(infe) >= 12 {
+			id, rest = uint32(binary.BigEndian.Uint16(infe[4:])), infe[8:]
+		} else if len(infe) >= 14 {
+			id, rest = binary.BigEndian.Uint32(infe[4:]), infe[10:]
+		}
+		if len(rest) >= 4 && string(rest[:4]) == "Exif" {
+			return id
+		}
+	}
+	return 0
+}
+
+// itemExtent returns the file offset and length of item id's first
+// extent from an iloc box's body.
+func itemExtent(b []byte, id uint32) (off, n uint64, ok bool) {
+	if len(b) < 8 {
+		return 0, 0, false
+	}
+	version := b[0]
+	offSize, lenSize := int(b[4]>>4), int(b[4]&0xf)
+	baseSize, idxSize := int(b[5]>>4), int(b[5]&0xf)
+	if version == 0 {
+		idxSize = 0
+	}
+	p := 6
+	read := func(size int) (uint64, bool) {
+		if size == 0 {
+			return 0, true
+		}
+		if p+size > len(b) {
+			return 0, false
+		}
+		v := beUint(b[p : p+size])
+		p += size
+		return v, true
+	}
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	count, ok := read(idSize)
+	for i := uint64(0); ok && i < count; i++ {
+		var item, base, extents uint64
+		item, ok = read(idSize)
+		if ok && version > 0 {
+			_, ok = read(2) // construction method
+		}
+		if ok {
+			_, ok = read(2) // data reference index
+		}
+		if ok {
+			base, ok = read(baseSize)
+		}
+		if ok {
+			extents, ok = read(2)
+		}
+		for e := uint64(0); ok && e < extents; e++ {
+			var eoff, elen uint64
+			if _, ok = read(idxSize); ok {
+				eoff, ok = read(offSize)
+			}
+			if ok {
+				elen, ok = read(lenSize)
+			}
+			if ok && e == 0 && uint32(item) == id {
+				return base + eoff, elen, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// exifTaken reads DateTimeOriginal, and OffsetTimeOriginal when
+// present, from the EXIF sub-IFD of TIFF data.
+func exifTaken(tiff []byte) (time.Time, bool) {
+	if len(tiff) < 8 {
+		return time.Time{}, false
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return time.Time{}, false
+	}
+	ptr, ok := tiffTag(tiff, bo, bo.Uint32(tiff[4:]), tagExifIFD)
+	if !ok || len(ptr) < 4 {
+		return time.Time{}, false
+	}
+	exif := bo.Uint32(ptr)
+	when, ok := tiffTag(tiff, bo, exif, tagDateTimeOriginal)
+	if !ok {
+		return time.Time{}, false
+	}
+	loc := time.Local
+	if zone, ok := tiffTag(tiff, bo, exif, tagOffsetOriginal); ok {
+		if t, err := time.Parse("-07:00", tiffString(zone)); err == nil {
+			loc = t.Location()
+		}
+	}
+	t, err := time.ParseInLocation("2006:01:02 15:04:05", tiffString(when), loc)
+	return t, err == nil
+}
+
+// tiffTag finds tag in the IFD at off and returns its value: inline
+// when it fits in 4 bytes, else where the entry points.
+func tiffTag(tiff []byte, bo binary.ByteOrder, off uint32, tag uint16) ([]byte, bool) {
+	if uint64(off)+2 > uint64(len(tiff)) {
+		return nil, false
+	}
+	n := int(bo.Uint16(tiff[off:]))
+	for i := range n {
+		e := uint64(off) + 2 + uint64(i)*12
+		if e+12 > uint64(len(tiff)) {
+			return nil, false
+		}
+		entry := tiff[e : e+12]
+		if bo.Uint16(entry) != tag {
+			continue
+		}
+		size := uint64(bo.Uint32(entry[4:])) * typeSize(bo.Uint16(entry[2:]))
+		if size <= 4 {
+			return entry[8 : 8+size], true
+		}
+		at := uint64(bo.Uint32(entry[8:]))
+		if at+size > uint64(len(tiff)) {
+			return nil, false
+		}
+		return tiff[at : at+size], true
+	}
+	return nil, false
+}
+
+// typeSize is the byte size of one value of a TIFF field type.
+func typeSize(typ uint16) uint64 {
+	switch typ {
+	case 3, 8: // SHORT, SSHORT
+		return 2
+	case 4, 9, 11: // LONG, SLONG, FLOAT
+		return 4
+	case 5, 10, 12: // RATIONAL, SRATIONAL, DOUBLE
+		return 8
+	}
+	return 1 // BYTE, ASCII, UNDEFINED
+}
+
+func tiffString(b []byte) string {
+	return strings.TrimRight(string(b), "\x00 ")
+}
@@ -0,0 +1,125 @@
+package peek
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// MarkdownRenderer writes the listing as Markdown tables, one for dirs
+// and one for files, ready to paste into a wiki or PR description.
+type MarkdownRenderer struct {
+	Title string // heading above the tables; none when empty
+}
+
+func (r MarkdownRenderer) Render(w io.Writer, entries []Entry) error {
+	dirs, files := Split(entries)
+	var b strings.Builder
+	if r.Title != "" {
+		fmt.Fprintf(&b, "## %s\n\n", mdCode(r.Title))
+	}
+	if len(dirs) > 0 {
+		b.WriteString("| Dir | Contents |\n| --- | ---: |\n")
+		for _, d := range dirs {
+			fmt.Fprintf(&b, "| %s/ | %s |\n", mdCell(d.Name), mdCell(subtitle(d)))
+		}
+		b.WriteString("\n")
+	}
+	if len(files) > 0 {
+		b.WriteString("| File | Size |\n| --- | ---: |\n")
+		for _, f := range files {
+			fmt.Fprintf(&b, "| %s | %s |\n", mdCell(f.Name), HumanSize(f.Size))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "_%s_\n", footerText(len(dirs), len(files)))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdCell escapes what would break a table cell or render as markup.
+// Line breaks, which would end the row, become spaces.
+func mdCell(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+var mdEscaper = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ",
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;")
+
+// mdCode is s as a code span, fenced with more backticks than any run
+// in s, on one line.
+func mdCode(s string) string {
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	run, longest := 0, 0
+	for _, c := range s {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	// A space at each end keeps a backtick there from joining the
+	// fence; CommonMark strips one from each side
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	fence := strings.Repeat("`", longest+1)
+	return fence + s + fence
+}
+
+// HTMLRenderer writes the listing as a standalone HTML page with the
+// dirs and files side by side.
+type HTMLRenderer struct {
+	Title string // page title and heading
+}
+
+func (r HTMLRenderer) Render(w io.Writer, entries []Entry) error {
+	dirs, files := Split(entries)
+	title := html.EscapeString(r.Title)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: ui-monospace, monospace; margin: 2em; }
+.panels { display: flex; gap: 2em; align-items: flex-start; }
+table { border-collapse: collapse; }
+th { text-align: left; border-bottom: 1px solid #888; }
+td, th { padding: 0.15em 0.8em; }
+td.meta { text-align: right; color: #777; }
+.dir { font-weight: bold; }
+.hidden { color: #999; }
+</style>
+</head>
+<body>
+<h2>%s</h2>
+<div class="panels">
+`, title, title)
+	table := func(head string, items []Entry, meta func(Entry) string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "<table>\n<tr><th>%s</th><th></th></tr>\n", head)
+		for _, e := range items {
+			class := ""
+			if e.IsDir {
+				class = "dir"
+			}
+			if e.Hidden {
+				class += " hidden"
+			}
+			fmt.Fprintf(&b, "<tr><td class=%q>%s</td><td class=\"meta\">%s</td></tr>\n",
+				strings.TrimSpace(class), html.EscapeString(e.Name), html.EscapeString(meta(e)))
+		}
+		b.WriteString("</table>\n")
+	}
+	table("Dirs", dirs, subtitle)
+	table("Files", files, func(e Entry) string { return HumanSize(e.Size) })
+	fmt.Fprintf(&b, "</div>\n<p>%s</p>\n</body>\n</html>\n", html.EscapeString(footerText(len(dirs), len(files))))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,74 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Units ParseAge accepts beyond those of time.ParseDuration
+var ageUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// ParseAge reads a human-friendly duration such as "2d", "1w", "36h"
+// or "1.5y": a number and one unit, s, m, h, d, w or y. Anything
+// time.ParseDuration understands, like "1h30m", is accepted too.
+func ParseAge(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	num := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyz")
+	unit, ok := ageUnits[s[len(num):]]
+	n, err := strconv.ParseFloat(num, 64)
+	n *= float64(unit)
+	if !ok || err != nil || !fitsInt64(n) {
+		return 0, fmt.Errorf("invalid age %q (e.g. 90m, 2d, 1w)", s)
+	}
+	return time.Duration(n), nil
+}
+
+// ParseSize reads a size such as "512", "10K", "1.5G" or "20MB", in
+// the same 1024-based units HumanSize prints.
+func ParseSize(s string) (int64, error) {
+	num := strings.TrimRight(strings.ToUpper(strings.TrimSpace(s)), "BI ")
+	shift := 0
+	if i := len(num) - 1; i >= 0 {
+		if n := strings.IndexByte("KMGT", num[i]); n >= 0 {
+			shift, num = 10*(n+1), num[:i]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	n *= float64(int64(1) << shift)
+	if err != nil || !fitsInt64(n) {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512, 10K, 1.5G)", s)
+	}
+	return int64(n), nil
+}
+
+// fitsInt64 reports whether n is finite, not negative and small enough
+// to convert to an int64; ParseFloat takes "inf", "nan" and "1e300".
+func fitsInt64(n float64) bool {
+	return n >= 0 && n < math.MaxInt64
+}
+
+// inSizeRange reports whether a file of size passes MinSize and MaxSize.
+func (s *Scanner) inSizeRange(size int64) bool {
+	return size >= s.MinSize && (s.MaxSize == 0 || size <= s.MaxSize)
+}
+
+// inTimeRange reports whether t passes the Scanner's NewerThan and
+// OlderThan cutoffs.
+func (s *Scanner) inTimeRange(t time.Time) bool {
+	if !s.NewerThan.IsZero() && !t.After(s.NewerThan) {
+		return false
+	}
+	if !s.OlderThan.IsZero() && !t.Before(s.OlderThan) {
+		return false
+	}
+	return true
+}
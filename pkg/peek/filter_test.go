@@ -0,0 +1,57 @@
+package peek
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"512", 512, true},
+		{"10K", 10 << 10, true},
+		{"1.5G", 3 << 29, true},
+		{"20MB", 20 << 20, true},
+		{"2 KiB", 2 << 10, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"inf", 0, false},
+		{"+Inf", 0, false},
+		{"nan", 0, false},
+		{"1e300", 0, false},
+		{"9000000T", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"90m", 90 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"2d", 48 * time.Hour, true},
+		{"1.5w", 252 * time.Hour, true},
+		{"", 0, false},
+		{"3q", 0, false},
+		{"-2d", 0, false},
+		{"1e300d", 0, false},
+		{"400y", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
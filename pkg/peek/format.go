@@ -0,0 +1,182 @@
+package peek
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Truncate shortens s to at most max display cells, ending in "…".
+// Widths are counted per grapheme cluster, as the terminal draws them,
+// so wide CJK runes, emoji sequences and combining marks stay whole.
+// Control characters are escaped first; see Sanitize.
+func Truncate(s string, max int) string {
+	if max < 4 {
+		max = 4
+	}
+	return ansi.Truncate(Sanitize(s), max, "…")
+}
+
+// Sanitize makes s safe to print to a terminal: control characters,
+// bidi overrides and invalid UTF-8 become visible escapes such as \n,
+// \e and \x9b, so a file name can't move the cursor or inject
+// escape sequences.
+func Sanitize(s string) string {
+	clean := true
+	for _, r := range s {
+		if r == utf8.RuneError || unicode.IsControl(r) || unicode.Is(unicode.Bidi_Control, r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == 0x1b:
+			b.WriteString(`\e`)
+		case r < 0x80 && unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case unicode.IsControl(r) || unicode.Is(unicode.Bidi_Control, r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// textWidth is the number of cells s occupies, measured the same way
+// lipgloss measures when it pads and joins panels.
+func textWidth(s string) int {
+	return ansi.StringWidth(s)
+}
+
+// subtitle is the metadata shown after a dir name.
+func subtitle(d Entry) string {
+	if d.Unavailable {
+		return "unavailable"
+	}
+	if d.About != "" && d.DirSized {
+		return d.About + "  " + HumanSize(d.DirSize)
+	}
+	if d.DirSized {
+		return HumanSize(d.DirSize)
+	}
+	if d.Pruned {
+		return "pruned"
+	}
+	if d.About != "" {
+		return d.About
+	}
+	if d.SubCapped {
+		return shortCount(d.SubDirs+d.SubFiles) + "+ entries"
+	}
+	return dirSubtitle(d.SubDirs, d.SubFiles)
+}
+
+// sizeLabel is the size shown after a file name, with any detected
+// kind and line count, or a dir's subtitle.
+func sizeLabel(e Entry) string {
+	if e.IsDir || e.Unavailable {
+		return subtitle(e)
+	}
+	label := HumanSize(e.Size)
+	switch {
+	case e.Counted && e.Lines == 1:
+		label = "1 line  " + label
+	case e.Counted:
+		label = fmt.Sprintf("%d lines  %s", e.Lines, label)
+	}
+	switch {
+	case e.Pages == 1:
+		label = "1 page  " + label
+	case e.Pages > 0:
+		label = fmt.Sprintf("%d pages  %s", e.Pages, label)
+	}
+	if e.Codec != "" {
+		label = e.Codec + "  " + label
+	}
+	if e.Duration > 0 {
+		label = playTime(e.Duration) + "  " + label
+	}
+	if !e.Taken.IsZero() {
+		label = e.Taken.Format("2006-01-02 15:04") + "  " + label
+	}
+	if d := dimensions(e); d != "" {
+		label = d + "  " + label
+	}
+	if e.Detected != "" {
+		label = e.Detected + "  " + label
+	}
+	return label
+}
+
+// shortCount writes n as 950, 10k or 2.5M.
+func shortCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprint(n)
+	case n < 1_000_000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1e3), ".0") + "k"
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1e6), ".0") + "M"
+}
+
+func dirSubtitle(subDirs, subFiles int) string {
+	if subDirs == 0 && subFiles == 0 {
+		return "empty"
+	}
+	var parts []string
+	if subDirs > 0 {
+		s := fmt.Sprintf("%d dir", subDirs)
+		if subDirs > 1 {
+			s += "s"
+		}
+		parts = append(parts, s)
+	}
+	if subFiles > 0 {
+		s := fmt.Sprintf("%d file", subFiles)
+		if subFiles > 1 {
+			s += "s"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// HumanSize formats b using binary units, e.g. "4.2 K" or "130 M".
+func HumanSize(b int64) string {
+	if b == 0 {
+		return "0 B"
+	}
+	units := []string{"B", "K", "M", "G", "T"}
+	i := int(math.Log(float64(b)) / math.Log(1024))
+	if i >= len(units) {
+		i = len(units) - 1
+	}
+	val := float64(b) / math.Pow(1024, float64(i))
+	if i == 0 {
+		return fmt.Sprintf("%d B", b)
+	}
+	if val >= 10 {
+		return fmt.Sprintf("%d %s", int(val), units[i])
+	}
+	return fmt.Sprintf("%.1f %s", val, units[i])
+}
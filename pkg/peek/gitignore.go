@@ -0,0 +1,208 @@
+package peek
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitIgnore holds the .gitignore rules in force for one directory and
+// the ones above it, in the order git applies them.
+type gitIgnore struct {
+	prefix string // from the rules' root to the fs paths being checked
+	rules  []ignoreRule
+}
+
+type ignoreRule struct {
+	base    string // dir of the .gitignore, relative to the root; "." for it
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignores returns the rules for listing dir, which resolve mapped to
+// name in fsys, or nil when GitIgnore and MarkIgnored are off. On the
+// OS filesystem the rules start at the enclosing repository's root, or
+// at dir itself outside one; elsewhere they start at the root of fsys.
+// MarkIgnored alone only applies inside a repository.
+func (s *Scanner) ignores(dir string, fsys fs.FS, name string) *gitIgnore {
+	if !s.GitIgnore && !s.MarkIgnored {
+		return nil
+	}
+	_, _, inArchive := splitArchive(dir)
+	if s.FS != nil || inArchive {
+		if !s.GitIgnore {
+			return nil
+		}
+		return loadIgnores(fsys, name)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return &gitIgnore{}
+	}
+	root, repo := abs, false
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			root, repo = d, true
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if !repo && !s.GitIgnore {
+		return nil
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return &gitIgnore{}
+	}
+	rootFS := os.DirFS(root)
+	g := loadIgnores(rootFS, filepath.ToSlash(rel))
+	if exclude, err := fs.ReadFile(rootFS, ".git/info/exclude"); err == nil {
+		g.rules = append(parseIgnore(".", string(exclude)), g.rules...)
+	}
+	g.prefix = filepath.ToSlash(rel)
+	return g
+}
+
+// loadIgnores reads the .gitignore files from the root of fsys down to dir.
+func loadIgnores(fsys fs.FS, dir string) *gitIgnore {
+	g := (&gitIgnore{}).load(fsys, ".")
+	acc := "."
+	for _, part := range strings.Split(dir, "/") {
+		if part == "." || part == "" {
+			continue
+		}
+		acc = path.Join(acc, part)
+		g = g.load(fsys, acc)
+	}
+	return g
+}
+
+// load returns g extended with the rules of dir/.gitignore, if any.
+// A nil g stays nil.
+func (g *gitIgnore) load(fsys fs.FS, dir string) *gitIgnore {
+	if g == nil {
+		return nil
+	}
+	data, err := fs.ReadFile(fsys, path.Join(dir, ".gitignore"))
+	if err != nil {
+		return g
+	}
+	rules := parseIgnore(path.Join(g.prefix, dir), string(data))
+	return &gitIgnore{prefix: g.prefix, rules: append(append([]ignoreRule(nil), g.rules...), rules...)}
+}
+
+// ignored reports whether the fs path p is excluded; the last matching
+// rule wins, so a later "!pattern" can bring a path back. The .git dir
+// itself is always hidden.
+func (g *gitIgnore) ignored(p string, isDir bool) bool {
+	if g == nil {
+		return false
+	}
+	if path.Base(p) == ".git" {
+		return true
+	}
+	rel := path.Join(g.prefix, p)
+	out := false
+	for _, r := range g.rules {
+		sub := rel
+		if r.base != "." {
+			var ok bool
+			if sub, ok = strings.CutPrefix(rel, r.base+"/"); !ok {
+				continue
+			}
+		}
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.re.MatchString(sub) {
+			out = !r.negate
+		}
+	}
+	return out
+}
+
+func parseIgnore(base, data string) []ignoreRule {
+	var rules []ignoreRule
+	sc := bufio.NewScanner(strings.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate, line = true, line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		// A slash anywhere but the end anchors the pattern to its dir
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		expr := globRegexp(line)
+		if !anchored {
+			expr = "(?:.*/)?" + expr
+		}
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			continue
+		}
+		r.re = re
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// globRegexp translates a gitignore glob, where "**" spans dirs and
+// "*" and "?" stay within one.
+func globRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
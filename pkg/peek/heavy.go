@@ -0,0 +1,126 @@
+package peek
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Heavy walks dir recursively and returns its n largest files, largest
+// first, each named by its path below dir. The dirs at the top are
+// walked concurrently, Workers at a time. ShowAll and Match apply as in
+// Scan. n below 1 gives none.
+func (s *Scanner) Heavy(dir string, n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+			pe.Path = dir
+		}
+		return nil, err
+	}
+
+	top := heaviest{n: n}
+	var subdirs []string
+	for _, d := range entries {
+		p := path.Join(root, d.Name())
+		switch {
+		case !s.ShowAll && isHidden(d):
+		case d.IsDir():
+			if !linkedDir(fsys, p, d) {
+				subdirs = append(subdirs, p)
+			}
+		default:
+			top.add(s.heavyEntry(root, p, d))
+		}
+	}
+
+	var mu sync.Mutex
+	s.parallel(len(subdirs), func(i int) {
+		local := heaviest{n: n}
+		fs.WalkDir(fsys, subdirs[i], func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			s.Progress.add(1)
+			if !s.ShowAll && isHidden(d) || p != subdirs[i] && linkedDir(fsys, p, d) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() {
+				local.add(s.heavyEntry(root, p, d))
+			}
+			return nil
+		})
+		mu.Lock()
+		for _, e := range local.files {
+			top.add(&e)
+		}
+		mu.Unlock()
+	})
+	return top.files, nil
+}
+
+// heavyEntry is the file at p, named relative to root, or nil when it
+// is not a regular file Match lets through.
+func (s *Scanner) heavyEntry(root, p string, d fs.DirEntry) *Entry {
+	if !d.Type().IsRegular() || !s.matches(d.Name()) {
+		return nil
+	}
+	info, err := d.Info()
+	if err != nil {
+		return nil
+	}
+	name := p
+	if root != "." {
+		name = strings.TrimPrefix(p, root+"/")
+	}
+	return &Entry{
+		Name:    name,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Mode:    info.Mode(),
+		Hidden:  isHidden(d),
+		Ext:     strings.TrimPrefix(path.Ext(d.Name()), "."),
+	}
+}
+
+// heaviest keeps the n largest files added, largest first and then by
+// name, so the result doesn't depend on the order the walks finish in.
+type heaviest struct {
+	n     int
+	files []Entry
+}
+
+func (h *heaviest) add(e *Entry) {
+	if e == nil {
+		return
+	}
+	if len(h.files) == h.n && !heavier(e, &h.files[h.n-1]) {
+		return
+	}
+	i := sort.Search(len(h.files), func(i int) bool { return heavier(e, &h.files[i]) })
+	h.files = append(h.files, Entry{})
+	copy(h.files[i+1:], h.files[i:])
+	h.files[i] = *e
+	if len(h.files) > h.n {
+		h.files = h.files[:h.n]
+	}
+}
+
+func heavier(a, b *Entry) bool {
+	if a.Size != b.Size {
+		return a.Size > b.Size
+	}
+	return a.Name < b.Name
+}
@@ -0,0 +1,27 @@
+package peek
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHeavy(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{-1, nil},
+		{0, nil},
+		{2, []string{"zz.bin", "src/main.go"}},
+		{3, []string{"zz.bin", "src/main.go", "src/util.go"}},
+	}
+	for _, tt := range tests {
+		got, err := (&Scanner{FS: testFS()}).Heavy(".", tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(names(got), tt.want) {
+			t.Errorf("Heavy(., %d) = %q, want %q", tt.n, names(got), tt.want)
+		}
+	}
+}
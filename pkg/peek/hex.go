@@ -0,0 +1,52 @@
+package peek
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HexBytes is how much of a binary file a hexdump shows by default.
+const HexBytes = 256
+
+// HexLines formats b like hexdump -C: the offset, in as few hex digits
+// as b needs but at least 4, the bytes in hex in groups of 8, and as
+// ASCII with dots for the rest. Lines hold 16 bytes, or 8 or 4 when
+// width is too narrow for that.
+func HexLines(b []byte, width int) []string {
+	digits := max(len(fmt.Sprintf("%x", max(len(b)-1, 0))), 4)
+	per := 16
+	for per > 4 && hexWidth(digits, per) > width {
+		per /= 2
+	}
+	var lines []string
+	for off := 0; off < len(b); off += per {
+		row := b[off:min(off+per, len(b))]
+		var hex, text strings.Builder
+		for i := range per {
+			if i > 0 {
+				hex.WriteByte(' ')
+				if i%8 == 0 {
+					hex.WriteByte(' ')
+				}
+			}
+			if i >= len(row) {
+				hex.WriteString("  ")
+				continue
+			}
+			fmt.Fprintf(&hex, "%02x", row[i])
+			if c := row[i]; c >= 0x20 && c < 0x7f {
+				text.WriteByte(c)
+			} else {
+				text.WriteByte('.')
+			}
+		}
+		lines = append(lines, CountStyle.Render(fmt.Sprintf("%0*x", digits, off))+"  "+
+			metaStyle.Render(hex.String())+"  "+fileNameStyle.Render(text.String()))
+	}
+	return lines
+}
+
+// hexWidth is the cells a HexLines line of per bytes takes.
+func hexWidth(digits, per int) int {
+	return digits + 2 + per*3 - 1 + (per-1)/8 + 2 + per
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package peek
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// isHidden reports whether d is a dotfile.
+func isHidden(d fs.DirEntry) bool {
+	return strings.HasPrefix(d.Name(), ".")
+}
@@ -0,0 +1,21 @@
+package peek
+
+import (
+	"io/fs"
+	"strings"
+	"syscall"
+)
+
+// isHidden reports whether d is a dotfile or carries the hidden or
+// system attribute, as Explorer treats them.
+func isHidden(d fs.DirEntry) bool {
+	if strings.HasPrefix(d.Name(), ".") {
+		return true
+	}
+	info, err := d.Info()
+	if err != nil {
+		return false
+	}
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	return ok && attrs.FileAttributes&(syscall.FILE_ATTRIBUTE_HIDDEN|syscall.FILE_ATTRIBUTE_SYSTEM) != 0
+}
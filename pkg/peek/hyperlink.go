@@ -0,0 +1,22 @@
+package peek
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+var hostname, _ = os.Hostname()
+
+// hyperlink wraps text in an OSC 8 link to the file dir/name, or returns
+// it unchanged when dir is empty. dir must be an absolute OS path.
+func hyperlink(dir, name, text string) string {
+	if dir == "" {
+		return text
+	}
+	u := url.URL{Scheme: "file", Host: hostname, Path: filepath.ToSlash(filepath.Join(dir, name))}
+	if filepath.VolumeName(dir) != "" {
+		u.Path = "/" + u.Path // file://host/C:/...
+	}
+	return "\x1b]8;;" + u.String() + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
@@ -0,0 +1,86 @@
+package peek
+
+import "strings"
+
+// IconSet selects the glyph drawn before each name.
+type IconSet int
+
+const (
+	NoIcons    IconSet = iota
+	NerdIcons          // needs a Nerd Font
+	EmojiIcons         // plain emoji for any font
+)
+
+// Nerd Font glyphs for well-known names and extensions
+var nerdByName = map[string]string{
+	"makefile":   "\ue779",
+	"dockerfile": "\uf308",
+	"license":    "\uf02d",
+	".gitignore": "\ue702",
+	"go.mod":     "\ue627",
+	"go.sum":     "\ue627",
+}
+
+var nerdByExt = map[string]string{
+	"go": "\ue627", "rs": "\ue7a8", "py": "\ue606", "rb": "\ue21e",
+	"js": "\ue74e", "mjs": "\ue74e", "ts": "\ue628", "tsx": "\ue7ba", "jsx": "\ue7ba",
+	"c": "\ue61e", "h": "\ue61e", "cpp": "\ue61d", "hpp": "\ue61d", "cs": "\ue648",
+	"java": "\ue738", "kt": "\ue634", "swift": "\ue755", "lua": "\ue620", "php": "\ue73d",
+	"sh": "\uf489", "bash": "\uf489", "zsh": "\uf489", "fish": "\uf489", "ps1": "\uf489",
+	"html": "\ue736", "css": "\ue749", "scss": "\ue749", "vue": "\ue6a0",
+	"md": "\ue609", "json": "\ue60b", "yaml": "\ue615", "yml": "\ue615", "toml": "\ue615",
+	"pdf": "\uf1c1", "exe": "\uf17a", "lock": "\uf023",
+}
+
+var nerdByKind = map[Kind]string{
+	KindOther:    "\uf15b",
+	KindCode:     "\uf121",
+	KindData:     "\ue615",
+	KindDocument: "\uf15c",
+	KindImage:    "\uf1c5",
+	KindAudio:    "\uf001",
+	KindVideo:    "\uf03d",
+	KindArchive:  "\uf410",
+}
+
+var emojiByKind = map[Kind]string{
+	KindOther:    "📄",
+	KindCode:     "📜",
+	KindData:     "🔧",
+	KindDocument: "📝",
+	KindImage:    "📷",
+	KindAudio:    "🎵",
+	KindVideo:    "🎬",
+	KindArchive:  "📦",
+}
+
+// Icon returns the glyph for e, or "" for NoIcons.
+func (s IconSet) Icon(e Entry) string {
+	switch s {
+	case NerdIcons:
+		switch {
+		case e.IsDir && e.IsSymlink:
+			return "\uf482"
+		case e.IsDir:
+			return "\uf07b"
+		case e.IsSymlink:
+			return "\uf481"
+		}
+		if icon, ok := nerdByName[strings.ToLower(e.Name)]; ok {
+			return icon
+		}
+		if icon, ok := nerdByExt[strings.ToLower(e.Ext)]; ok {
+			return icon
+		}
+		return nerdByKind[KindOf(e)]
+	case EmojiIcons:
+		switch {
+		case e.IsSymlink:
+			return "🔗"
+		case e.IsDir:
+			return "📁"
+		}
+		return emojiByKind[KindOf(e)]
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package peek
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSONRenderer writes the listing as an indented JSON array, or as
+// {"path": ..., "entries": [...]} when Path is set.
+type JSONRenderer struct {
+	Path string
+}
+
+type jsonEntry struct {
+	Name     string     `json:"name"`
+	Type     string     `json:"type"` // "dir" or "file"
+	Size     int64      `json:"size"`
+	Hidden   bool       `json:"hidden"`
+	Symlink  bool       `json:"symlink"`
+	Pinned   bool       `json:"pinned,omitempty"`
+	Target   string     `json:"target,omitempty"`
+	Unavail  bool       `json:"unavailable,omitempty"` // timed out; only name and type are known
+	SubDirs  *int       `json:"sub_dirs,omitempty"`
+	SubFiles *int       `json:"sub_files,omitempty"`
+	Capped   bool       `json:"sub_capped,omitempty"` // counts stopped short
+	DirSize  *int64     `json:"dir_size,omitempty"`
+	About    string     `json:"about,omitempty"`    // from the dir's .peek or .dirinfo
+	Lines    *int       `json:"lines,omitempty"`    // text files, with --lines
+	Binary   *bool      `json:"binary,omitempty"`   // with --sniff
+	Detected string     `json:"detected,omitempty"` // kind sniffed from the content
+	Width    int        `json:"width,omitempty"`    // images
+	Height   int        `json:"height,omitempty"`
+	Duration float64    `json:"duration,omitempty"` // seconds, audio and video
+	Codec    string     `json:"codec,omitempty"`
+	Taken    *time.Time `json:"taken,omitempty"` // EXIF capture time of photos
+	Pages    int        `json:"pages,omitempty"` // PDFs
+	Preview  []string   `json:"preview,omitempty"`
+}
+
+func (r JSONRenderer) Render(w io.Writer, entries []Entry) error {
+	out := make([]jsonEntry, 0, len(entries))
+	for _, e := range entries {
+		je := jsonEntry{
+			Name:    e.Name,
+			Type:    "file",
+			Size:    e.Size,
+			Hidden:  e.Hidden,
+			Symlink: e.IsSymlink,
+			Pinned:  e.Pinned,
+			Target:  e.Target,
+			Unavail: e.Unavailable,
+			Preview: e.Preview,
+			Width:   e.Width,
+			Height:  e.Height,
+			Codec:   e.Codec,
+			Pages:   e.Pages,
+		}
+		if e.Duration > 0 {
+			je.Duration = e.Duration.Seconds()
+		}
+		if !e.Taken.IsZero() {
+			je.Taken = &e.Taken
+		}
+		if e.Counted {
+			je.Lines = &e.Lines
+		}
+		if e.Sniffed {
+			je.Binary, je.Detected = &e.Binary, e.Detected
+		}
+		if e.IsDir {
+			je.Type = "dir"
+			if !e.Unavailable {
+				je.SubDirs, je.SubFiles = &e.SubDirs, &e.SubFiles
+			}
+			je.Capped, je.About = e.SubCapped, e.About
+			if e.DirSized {
+				je.DirSize = &e.DirSize
+			}
+		}
+		out = append(out, je)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if r.Path != "" {
+		return enc.Encode(struct {
+			Path    string      `json:"path"`
+			Entries []jsonEntry `json:"entries"`
+		}{r.Path, out})
+	}
+	return enc.Encode(out)
+}
@@ -0,0 +1,43 @@
+package peek
+
+import "strings"
+
+// Kind is a coarse classification of a file by its extension.
+type Kind int
+
+const (
+	KindOther Kind = iota
+	KindCode
+	KindData // config and structured data
+	KindDocument
+	KindImage
+	KindAudio
+	KindVideo
+	KindArchive
+)
+
+var kindByExt = map[string]Kind{}
+
+func init() {
+	for kind, exts := range map[Kind]string{
+		KindCode:     "go c h cc cpp cxx hpp cs java kt kts scala rs py rb php pl lua js mjs cjs jsx ts tsx vue svelte swift m mm dart zig nim hs ml ex exs erl clj sh bash zsh fish ps1 bat cmd sql r jl html htm css scss sass less",
+		KindData:     "json jsonc yaml yml toml ini cfg conf xml csv tsv env lock properties plist proto graphql",
+		KindDocument: "txt md markdown rst org tex pdf doc docx odt rtf xls xlsx ods ppt pptx odp epub log",
+		KindImage:    "png jpg jpeg gif bmp webp svg ico tif tiff heic heif avif psd raw cr2 nef",
+		KindAudio:    "mp3 wav flac ogg oga opus m4a aac wma aiff",
+		KindVideo:    "mp4 mkv mov avi webm wmv flv m4v mpg mpeg 3gp",
+		KindArchive:  "zip tar gz tgz bz2 xz zst 7z rar jar war deb rpm dmg iso apk whl",
+	} {
+		for _, ext := range strings.Fields(exts) {
+			kindByExt[ext] = kind
+		}
+	}
+}
+
+// KindOf classifies e by extension; dirs are always KindOther.
+func KindOf(e Entry) Kind {
+	if e.IsDir {
+		return KindOther
+	}
+	return kindByExt[strings.ToLower(e.Ext)]
+}
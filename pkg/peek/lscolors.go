@@ -0,0 +1,136 @@
+package peek
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LSColors holds the name styles of an LS_COLORS / dircolors database.
+type LSColors struct {
+	types    map[string]lipgloss.Style // di, ln, ex, fi, ...
+	suffixes map[string]lipgloss.Style // lower-cased, e.g. ".tar.gz"
+}
+
+// ParseLSColors reads the LS_COLORS format, e.g. "di=01;34:*.go=32".
+// Entries that don't parse are skipped.
+func ParseLSColors(s string) *LSColors {
+	lc := &LSColors{types: map[string]lipgloss.Style{}, suffixes: map[string]lipgloss.Style{}}
+	for _, field := range strings.Split(s, ":") {
+		key, codes, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			continue
+		}
+		style, ok := sgrStyle(codes)
+		if !ok {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(key, "*"); ok {
+			lc.suffixes[strings.ToLower(suffix)] = style
+		} else {
+			lc.types[key] = style
+		}
+	}
+	return lc
+}
+
+// Style returns the style for e: "ln" for symlinks, "di" for dirs, and
+// for files the longest matching suffix, then "ex" for executables,
+// then "fi". ok is false when no rule applies.
+func (lc *LSColors) Style(e Entry) (style lipgloss.Style, ok bool) {
+	if lc == nil {
+		return style, false
+	}
+	switch {
+	case e.IsSymlink:
+		style, ok = lc.types["ln"]
+		return style, ok
+	case e.IsDir:
+		style, ok = lc.types["di"]
+		return style, ok
+	}
+	name, best := strings.ToLower(e.Name), 0
+	for suffix, s := range lc.suffixes {
+		if len(suffix) > best && strings.HasSuffix(name, suffix) {
+			style, ok, best = s, true, len(suffix)
+		}
+	}
+	if ok {
+		return style, true
+	}
+	if e.Executable() {
+		if style, ok = lc.types["ex"]; ok {
+			return style, true
+		}
+	}
+	style, ok = lc.types["fi"]
+	return style, ok
+}
+
+// sgrStyle converts SGR codes such as "01;38;5;208" to a style.
+func sgrStyle(codes string) (lipgloss.Style, bool) {
+	style := lipgloss.NewStyle()
+	parts := strings.Split(codes, ";")
+	for i := 0; i < len(parts); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return style, false
+		}
+		switch {
+		case n == 0:
+		case n == 1:
+			style = style.Bold(true)
+		case n == 2:
+			style = style.Faint(true)
+		case n == 3:
+			style = style.Italic(true)
+		case n == 4:
+			style = style.Underline(true)
+		case n == 5:
+			style = style.Blink(true)
+		case n == 7:
+			style = style.Reverse(true)
+		case n >= 30 && n <= 37:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(n - 30)))
+		case n >= 90 && n <= 97:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(n - 90 + 8)))
+		case n >= 40 && n <= 47:
+			style = style.Background(lipgloss.Color(strconv.Itoa(n - 40)))
+		case n >= 100 && n <= 107:
+			style = style.Background(lipgloss.Color(strconv.Itoa(n - 100 + 8)))
+		case n == 38 || n == 48:
+			c, used, ok := extendedColor(parts[i+1:])
+			if !ok {
+				return style, false
+			}
+			i += used
+			if n == 38 {
+				style = style.Foreground(c)
+			} else {
+				style = style.Background(c)
+			}
+		}
+	}
+	return style, true
+}
+
+// extendedColor reads the "5;N" or "2;R;G;B" that follows 38 or 48.
+func extendedColor(parts []string) (lipgloss.Color, int, bool) {
+	nums := make([]int, 0, 4)
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "", 0, false
+		}
+		nums = append(nums, n)
+	}
+	switch {
+	case len(nums) >= 2 && nums[0] == 5:
+		return lipgloss.Color(strconv.Itoa(nums[1])), 2, true
+	case len(nums) >= 4 && nums[0] == 2:
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", nums[1], nums[2], nums[3])), 4, true
+	}
+	return "", 0, false
+}
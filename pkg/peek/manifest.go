@@ -0,0 +1,183 @@
+package peek
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest writes a SHA-256 line for each file Scan lists in dir, in
+// the "hash  name" format sha256sum reads, escaping names the way it
+// does. Symlinks are followed; files that can't be read are an error.
+func (s *Scanner) Manifest(dir string, w io.Writer) error {
+	fsys, name, err := s.resolve(dir)
+	if err != nil {
+		return err
+	}
+	entries, _, err := s.scanFS(fsys, name, s.ignores(dir, fsys, name))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir {
+			continue
+		}
+		sum, err := sha256File(fsys, path.Join(name, e.Name))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, manifestLine(sum, e.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify checks the manifest file against the dir holding it. It
+// returns that dir's listing with each file's Note set to "ok",
+// "mismatch" or "unlisted", followed by entries for files the manifest
+// names but the dir lacks, noted "missing". Mismatched and missing
+// files are Flagged, and bad counts them.
+func (s *Scanner) Verify(manifest string) (entries []Entry, bad int, err error) {
+	f, err := os.Open(manifest)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	sums := map[string]string{}
+	var order []string
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		escaped := strings.HasPrefix(line, `\`)
+		if escaped {
+			line = line[1:]
+		}
+		sum, name, ok := strings.Cut(line, " ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return nil, 0, fmt.Errorf("%s:%d: not a sha256 line", manifest, n)
+		}
+		name = strings.TrimPrefix(name, " ")
+		name = strings.TrimPrefix(name, "*") // sha256sum's binary mode marker
+		if escaped {
+			if name, ok = unescapeName(name); !ok {
+				return nil, 0, fmt.Errorf("%s:%d: bad escape in name", manifest, n)
+			}
+		}
+		sums[name] = strings.ToLower(sum)
+		order = append(order, name)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	dir := filepath.Dir(manifest)
+	fsys, name, err := s.resolve(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Hidden files the manifest names are checked even without ShowAll
+	all := *s
+	all.ShowAll = true
+	listed, _, err := all.scanFS(fsys, name, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	self := filepath.Base(manifest)
+	present := map[string]bool{self: true}
+	for _, e := range listed {
+		if e.IsDir {
+			if !e.Hidden || s.ShowAll {
+				entries = append(entries, e)
+			}
+			continue
+		}
+		if e.Name == self {
+			continue
+		}
+		present[e.Name] = true
+		want, ok := sums[e.Name]
+		if e.Hidden && !s.ShowAll && !ok {
+			continue
+		}
+		if !ok {
+			e.Note = "unlisted"
+		} else if got, err := sha256File(fsys, path.Join(name, e.Name)); err != nil || got != want {
+			e.Note, e.Flagged = "mismatch", true
+			bad++
+		} else {
+			e.Note = "ok"
+		}
+		entries = append(entries, e)
+	}
+	for _, n := range order {
+		if !present[n] {
+			entries = append(entries, Entry{Name: n, Ext: strings.TrimPrefix(path.Ext(n), "."), Note: "missing", Flagged: true})
+			bad++
+		}
+	}
+	return entries, bad, nil
+}
+
+// nameEscaper escapes names as sha256sum does for lines it marks with
+// a leading backslash.
+var nameEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+// manifestLine formats one manifest line. A name holding a backslash
+// or line break is escaped and the line marked, so it can't split or
+// be misread.
+func manifestLine(sum, name string) string {
+	if !strings.ContainsAny(name, "\\\n\r") {
+		return sum + "  " + name
+	}
+	return `\` + sum + "  " + nameEscaper.Replace(name)
+}
+
+// unescapeName undoes manifestLine's escaping, failing on anything
+// sha256sum wouldn't have written.
+func unescapeName(s string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i++; i == len(s) {
+			return "", false
+		}
+		switch s[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+func sha256File(fsys fs.FS, name string) (string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,105 @@
+package peek
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifestEscapes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"plain":     {Data: []byte("c\n")},
+		`back\code`: {Data: []byte("a\n")},
+		"two\nrows": {Data: []byte("b\n")},
+	}
+	var b bytes.Buffer
+	if err := (&Scanner{FS: fsys, Sort: SortName}).Manifest(".", &b); err != nil {
+		t.Fatal(err)
+	}
+	// sha256sum's own output for these files
+	want := `\87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7  back\\code
+a3a5e715f0cc574a73c3f9bebb6bc24f32ffd5b67b387244c2c909da779a1478  plain
+\0263829989b6fd954f72baaf2fc64bc2e2f01d692d4de72986ea808f6e99813f  two\nrows
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestUnescapeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{`plain`, "plain", true},
+		{`a\\b`, `a\b`, true},
+		{`a\nb\rc`, "a\nb\rc", true},
+		{`trailing\`, "", false},
+		{`a\tb`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := unescapeName(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("unescapeName(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("names with backslashes and line breaks")
+	}
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"plain":     "a\n",
+		`back\code`: "b\n",
+		"two\nrows": "c\n",
+		"changed":   "d\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var b bytes.Buffer
+	if err := (&Scanner{}).Manifest(dir, &b); err != nil {
+		t.Fatal(err)
+	}
+	b.WriteString("0000000000000000000000000000000000000000000000000000000000000000  gone\n")
+	manifest := filepath.Join(dir, "SHA256SUMS")
+	if err := os.WriteFile(manifest, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "changed"), []byte("e\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, bad, err := (&Scanner{}).Verify(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := map[string]string{}
+	for _, e := range entries {
+		notes[e.Name] = e.Note
+	}
+	want := map[string]string{
+		"plain":     "ok",
+		`back\code`: "ok",
+		"two\nrows": "ok",
+		"changed":   "mismatch",
+		"gone":      "missing",
+	}
+	for name, note := range want {
+		if notes[name] != note {
+			t.Errorf("%q noted %q, want %q", name, notes[name], note)
+		}
+	}
+	if len(notes) != len(want) {
+		t.Errorf("got notes %q, want %q", notes, want)
+	}
+	if bad != 2 {
+		t.Errorf("bad = %d, want 2", bad)
+	}
+}
@@ -0,0 +1,77 @@
+package peek
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+)
+
+// imageSize reads the width and height from the header of the image
+// file name: PNG, JPEG and GIF through image.DecodeConfig, BMP and WebP
+// by hand. ok is false for other files and broken headers.
+func imageSize(fsys fs.FS, name, ext string) (w, h int, ok bool) {
+	switch ext {
+	case "png", "jpg", "jpeg", "gif", "bmp", "webp":
+	default:
+		return 0, 0, false
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	switch ext {
+	case "bmp":
+		var hdr [26]byte
+		if _, err := io.ReadFull(f, hdr[:]); err != nil || string(hdr[:2]) != "BM" {
+			return 0, 0, false
+		}
+		w = int(int32(binary.LittleEndian.Uint32(hdr[18:])))
+		h = int(int32(binary.LittleEndian.Uint32(hdr[22:])))
+		return w, max(h, -h), w > 0 && h != 0
+	case "webp":
+		return webpSize(f)
+	}
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, false
+	}
+	return cfg.Width, cfg.Height, true
+}
+
+// webpSize reads a WebP's canvas size from its first chunk: lossy
+// (VP8), lossless (VP8L) or extended (VP8X).
+func webpSize(r io.Reader) (w, h int, ok bool) {
+	var b [30]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil || string(b[:4]) != "RIFF" || string(b[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	le24 := func(p []byte) int { return int(p[0]) | int(p[1])<<8 | int(p[2])<<16 }
+	switch string(b[12:16]) {
+	case "VP8 ":
+		w = int(binary.LittleEndian.Uint16(b[26:])) & 0x3fff
+		h = int(binary.LittleEndian.Uint16(b[28:])) & 0x3fff
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(b[21:])
+		w, h = int(bits&0x3fff)+1, int(bits>>14&0x3fff)+1
+	case "VP8X":
+		w, h = le24(b[24:])+1, le24(b[27:])+1
+	default:
+		return 0, 0, false
+	}
+	return w, h, w > 0 && h > 0
+}
+
+// dimensions is "1920×1080" for an image with a known size, else "".
+func dimensions(e Entry) string {
+	if e.Width == 0 || e.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d×%d", e.Width, e.Height)
+}
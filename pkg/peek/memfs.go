@@ -0,0 +1,176 @@
+package peek
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// memFS is a read-only, metadata-only fs.FS built from archive members.
+// Parent dirs missing from the archive are implied.
+type memFS struct {
+	nodes map[string]*memNode // keyed by fs path; "." is the root
+}
+
+type memNode struct {
+	info     memInfo
+	target   string
+	children []string // names, dirs only
+}
+
+func newMemFS(members []member) *memFS {
+	m := &memFS{nodes: map[string]*memNode{
+		".": {info: memInfo{name: ".", mode: fs.ModeDir | 0o755}},
+	}}
+	for _, mb := range members {
+		m.mkdirAll(path.Dir(mb.name))
+		n := m.nodes[mb.name]
+		if n == nil {
+			n = &memNode{}
+			m.nodes[mb.name] = n
+			parent := m.nodes[path.Dir(mb.name)]
+			parent.children = append(parent.children, path.Base(mb.name))
+		}
+		n.info = memInfo{name: path.Base(mb.name), mode: mb.mode, size: mb.size, modTime: mb.modTime}
+		n.target = mb.target
+	}
+	return m
+}
+
+// mkdirAll adds implied dirs for p and its parents.
+func (m *memFS) mkdirAll(p string) {
+	if _, ok := m.nodes[p]; ok {
+		return
+	}
+	m.mkdirAll(path.Dir(p))
+	m.nodes[p] = &memNode{info: memInfo{name: path.Base(p), mode: fs.ModeDir | 0o755}}
+	parent := m.nodes[path.Dir(p)]
+	parent.children = append(parent.children, path.Base(p))
+}
+
+func (m *memFS) lookup(op, name string) (*memNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	n, ok := m.nodes[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return n, nil
+}
+
+// follow resolves symlinks within the archive
+func (m *memFS) follow(op, name string) (string, *memNode, error) {
+	for range 16 {
+		n, err := m.lookup(op, name)
+		if err != nil {
+			return "", nil, err
+		}
+		if n.info.mode&fs.ModeSymlink == 0 {
+			return name, n, nil
+		}
+		if path.IsAbs(n.target) {
+			return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		name = path.Join(path.Dir(name), n.target)
+		if strings.HasPrefix(name, "..") {
+			return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return "", nil, &fs.PathError{Op: op, Path: name, Err: errors.New("too many links")}
+}
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	name, n, err := m.follow("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{fsys: m, name: name, node: n}, nil
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	_, n, err := m.follow("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return n.info, nil
+}
+
+func (m *memFS) Lstat(name string) (fs.FileInfo, error) {
+	n, err := m.lookup("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return n.info, nil
+}
+
+func (m *memFS) ReadLink(name string) (string, error) {
+	n, err := m.lookup("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if n.info.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return n.target, nil
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name, n, err := m.follow("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !n.info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	names := append([]string(nil), n.children...)
+	sort.Strings(names)
+	entries := make([]fs.DirEntry, len(names))
+	for i, child := range names {
+		entries[i] = fs.FileInfoToDirEntry(m.nodes[path.Join(name, child)].info)
+	}
+	return entries, nil
+}
+
+type memFile struct {
+	fsys   *memFS
+	name   string
+	node   *memNode
+	listed bool
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.node.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+func (f *memFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: f.name, Err: errors.ErrUnsupported}
+}
+
+func (f *memFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if f.listed {
+		if n > 0 {
+			return nil, io.EOF
+		}
+		return nil, nil
+	}
+	f.listed = true
+	return f.fsys.ReadDir(f.name)
+}
+
+type memInfo struct {
+	name    string
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() fs.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package peek
+
+import "io/fs"
+
+func ownerOf(fs.FS, string, fs.FileInfo) (owner, group string) {
+	return "", ""
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package peek
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+var (
+	userNames  sync.Map // uid -> name
+	groupNames sync.Map // gid -> name
+)
+
+// ownerOf resolves the owning user and group of info, falling back to
+// the numeric ids.
+func ownerOf(_ fs.FS, _ string, info fs.FileInfo) (owner, group string) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+	owner = lookupID(&userNames, st.Uid, func(id string) (string, error) {
+		u, err := user.LookupId(id)
+		if err != nil {
+			return "", err
+		}
+		return u.Username, nil
+	})
+	group = lookupID(&groupNames, st.Gid, func(id string) (string, error) {
+		g, err := user.LookupGroupId(id)
+		if err != nil {
+			return "", err
+		}
+		return g.Name, nil
+	})
+	return owner, group
+}
+
+// lookupID caches lookup(id) in names, using the id itself when it fails.
+func lookupID(names *sync.Map, id uint32, lookup func(string) (string, error)) string {
+	if name, ok := names.Load(id); ok {
+		return name.(string)
+	}
+	name := strconv.FormatUint(uint64(id), 10)
+	if n, err := lookup(name); err == nil {
+		name = n
+	}
+	names.Store(id, name)
+	return name
+}
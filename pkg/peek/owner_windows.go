@@ -0,0 +1,45 @@
+package peek
+
+import (
+	"io/fs"
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+var accountNames sync.Map // SID string -> account name
+
+// ownerOf resolves the owner and primary group SIDs from the security
+// descriptor of name to account names, falling back to the SID. Only
+// OS files have one; archive members and other filesystems get "".
+func ownerOf(fsys fs.FS, name string, _ fs.FileInfo) (owner, group string) {
+	p, ok := osPath(fsys, name)
+	if !ok {
+		return "", ""
+	}
+	sd, err := windows.GetNamedSecurityInfo(p, windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.GROUP_SECURITY_INFORMATION)
+	if err != nil {
+		return "", ""
+	}
+	if sid, _, err := sd.Owner(); err == nil && sid != nil {
+		owner = accountName(sid)
+	}
+	if sid, _, err := sd.Group(); err == nil && sid != nil {
+		group = accountName(sid)
+	}
+	return owner, group
+}
+
+func accountName(sid *windows.SID) string {
+	key := sid.String()
+	if name, ok := accountNames.Load(key); ok {
+		return name.(string)
+	}
+	name := key
+	if account, _, _, err := sid.LookupAccount(""); err == nil {
+		name = account
+	}
+	accountNames.Store(key, name)
+	return name
+}
@@ -0,0 +1,29 @@
+package peek
+
+import (
+	"bufio"
+	"io"
+)
+
+// PathRenderer writes the path of each entry on a line of its own,
+// dirs then files in the listing's order, with nothing else: input for
+// fzf, xargs and the like. Names are written as they are, unescaped.
+type PathRenderer struct {
+	Dir string // put before each name: "" or a path ending in a separator
+
+	// NUL ends each path with a NUL byte instead of a newline, for
+	// xargs -0, so names holding newlines survive.
+	NUL bool
+}
+
+func (r PathRenderer) Render(w io.Writer, entries []Entry) error {
+	end := "\n"
+	if r.NUL {
+		end = "\x00"
+	}
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		bw.WriteString(r.Dir + e.Name + end)
+	}
+	return bw.Flush()
+}
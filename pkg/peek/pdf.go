@@ -0,0 +1,119 @@
+package peek
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/fs"
+	"regexp"
+	"strconv"
+)
+
+// Limits on the work a PDF can make: bytes read and, across all its
+// object streams, inflated; matches looked at per search; and how far
+// from a match its dict or stream is looked for.
+const (
+	maxPDF        = 64 << 20
+	maxPDFMatches = 100
+	maxPDFDict    = 1 << 20
+)
+
+var (
+	pdfPages  = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pdfCount  = regexp.MustCompile(`/Count\s+(\d+)`)
+	pdfObjStm = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+)
+
+// pdfPageCount reads the number of pages of the PDF name from the
+// /Count of its page tree. Trees split into nodes have a count per
+// node; the root's, the largest, is the total. PDF 1.5 files can keep
+// the tree in compressed object streams, which are inflated to look.
+func pdfPageCount(fsys fs.FS, name string) (int, bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	b, err := io.ReadAll(io.LimitReader(f, maxPDF))
+	if err != nil || !bytes.HasPrefix(b, []byte("%PDF-")) {
+		return 0, false
+	}
+	n := pagesIn(b)
+	budget := int64(maxPDF)
+	for _, m := range pdfObjStm.FindAllIndex(b, maxPDFMatches) {
+		s := objectStream(b, m[1], budget)
+		n = max(n, pagesIn(s))
+		if budget -= int64(len(s)); budget <= 0 {
+			break
+		}
+	}
+	return n, n > 0
+}
+
+// pagesIn is the largest /Count of the /Type /Pages dicts in b.
+func pagesIn(b []byte) int {
+	n := 0
+	for _, m := range pdfPages.FindAllIndex(b, maxPDFMatches) {
+		for _, c := range pdfCount.FindAllSubmatch(enclosingDict(b, m[0]), -1) {
+			if v, err := strconv.Atoi(string(c[1])); err == nil {
+				n = max(n, v)
+			}
+		}
+	}
+	return n
+}
+
+// enclosingDict is the << ... >> dict around b[at], outer dicts
+// excepted, or nil when it isn't closed within maxPDFDict of at.
+func enclosingDict(b []byte, at int) []byte {
+	start, depth := -1, 0
+	for i := at - 1; i > max(at-maxPDFDict, 0); i-- {
+		if b[i-1] == '>' && b[i] == '>' {
+			depth++
+			i--
+		} else if b[i-1] == '<' && b[i] == '<' {
+			if depth == 0 {
+				start = i - 1
+				break
+			}
+			depth--
+			i--
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	depth = 0
+	for i := at; i+1 < min(len(b), at+maxPDFDict); i++ {
+		if b[i] == '<' && b[i+1] == '<' {
+			depth++
+			i++
+		} else if b[i] == '>' && b[i+1] == '>' {
+			if depth == 0 {
+				return b[start : i+2]
+			}
+			depth--
+			i++
+		}
+	}
+	return nil
+}
+
+// objectStream inflates up to limit bytes of the stream that follows
+// the dict at b[at:], assuming FlateDecode, the filter object streams
+// are written with.
+func objectStream(b []byte, at int, limit int64) []byte {
+	i := bytes.Index(b[at:min(len(b), at+maxPDFDict)], []byte("stream"))
+	if i < 0 {
+		return nil
+	}
+	data := b[at+i+len("stream"):]
+	data = bytes.TrimLeft(data, "\r\n")
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+	s, _ := io.ReadAll(io.LimitReader(zr, limit))
+	return s
+}
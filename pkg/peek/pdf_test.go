@@ -0,0 +1,51 @@
+package peek
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+	"testing/fstest"
+)
+
+func objStm(body []byte) []byte {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(body)
+	zw.Close()
+	return append(append([]byte("1 0 obj << /Type /ObjStm /Filter /FlateDecode >> stream\n"), z.Bytes()...), "\nendstream endobj\n"...)
+}
+
+func TestPDFPageCount(t *testing.T) {
+	plain := "%PDF-1.4\n1 0 obj << /Type /Pages /Kids [2 0 R 3 0 R] /Count 2 >> endobj\n" +
+		"2 0 obj << /Type /Page /Parent 1 0 R >> endobj\n"
+	compressed := append([]byte("%PDF-1.5\n"), objStm([]byte("<< /Type /Pages /Count 12 /Kids [] >>"))...)
+	// Streams that each inflate to 8 MB of zeros, more of them than
+	// are looked at, after the one holding the tree
+	bomb := bytes.Clone(compressed)
+	zeros := objStm(make([]byte, 8<<20))
+	for range maxPDFMatches + 50 {
+		bomb = append(bomb, zeros...)
+	}
+
+	fsys := fstest.MapFS{
+		"plain.pdf":      {Data: []byte(plain)},
+		"compressed.pdf": {Data: compressed},
+		"bomb.pdf":       {Data: bomb},
+		"not.pdf":        {Data: []byte("<< /Type /Pages /Count 3 >>")},
+	}
+	tests := []struct {
+		name string
+		want int
+		ok   bool
+	}{
+		{"plain.pdf", 2, true},
+		{"compressed.pdf", 12, true},
+		{"bomb.pdf", 12, true},
+		{"not.pdf", 0, false},
+	}
+	for _, tt := range tests {
+		if n, ok := pdfPageCount(fsys, tt.name); n != tt.want || ok != tt.ok {
+			t.Errorf("%s: got %d, %v; want %d, %v", tt.name, n, ok, tt.want, tt.ok)
+		}
+	}
+}
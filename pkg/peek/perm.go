@@ -0,0 +1,118 @@
+package peek
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// permString formats m like ls -l: a type letter and the nine rwx bits,
+// with setuid and setgid as s/S in the x slots and sticky as t/T.
+func permString(m fs.FileMode) string {
+	var b strings.Builder
+	switch {
+	case m&fs.ModeDir != 0:
+		b.WriteByte('d')
+	case m&fs.ModeSymlink != 0:
+		b.WriteByte('l')
+	case m&fs.ModeNamedPipe != 0:
+		b.WriteByte('p')
+	case m&fs.ModeSocket != 0:
+		b.WriteByte('s')
+	case m&fs.ModeCharDevice != 0:
+		b.WriteByte('c')
+	case m&fs.ModeDevice != 0:
+		b.WriteByte('b')
+	default:
+		b.WriteByte('-')
+	}
+	special := [3]struct {
+		set        bool
+		on, noExec byte
+	}{
+		{m&fs.ModeSetuid != 0, 's', 'S'},
+		{m&fs.ModeSetgid != 0, 's', 'S'},
+		{m&fs.ModeSticky != 0, 't', 'T'},
+	}
+	for i, sp := range special {
+		bits := m.Perm() >> (6 - 3*i)
+		b.WriteByte(bit(bits&4 != 0, 'r'))
+		b.WriteByte(bit(bits&2 != 0, 'w'))
+		exec := bits&1 != 0
+		switch {
+		case sp.set && exec:
+			b.WriteByte(sp.on)
+		case sp.set:
+			b.WriteByte(sp.noExec)
+		default:
+			b.WriteByte(bit(exec, 'x'))
+		}
+	}
+	return b.String()
+}
+
+func bit(set bool, c byte) byte {
+	if set {
+		return c
+	}
+	return '-'
+}
+
+// octalPerm formats m's permissions as chmod takes them, e.g. "755",
+// led by a fourth digit for setuid, setgid and sticky ("4755").
+func octalPerm(m fs.FileMode) string {
+	var special fs.FileMode
+	if m&fs.ModeSetuid != 0 {
+		special |= 4
+	}
+	if m&fs.ModeSetgid != 0 {
+		special |= 2
+	}
+	if m&fs.ModeSticky != 0 {
+		special |= 1
+	}
+	if special != 0 {
+		return fmt.Sprintf("%o%03o", special, m.Perm())
+	}
+	return fmt.Sprintf("%03o", m.Perm())
+}
+
+// hasSpecialBits reports whether m has setuid, setgid or sticky set.
+func hasSpecialBits(m fs.FileMode) bool {
+	return m&(fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky) != 0
+}
+
+// styleMeta renders the subtitle sub in st, picking out the special
+// bits of a leading permissions field in specialStyle.
+func (r PanelRenderer) styleMeta(st lipgloss.Style, sub string, e Entry) string {
+	perm := r.perm(e)
+	if !r.Long || !hasSpecialBits(e.Mode) || !strings.HasPrefix(sub, perm) {
+		return st.Render(sub)
+	}
+	var b strings.Builder
+	start := 0
+	for i, c := range perm {
+		special := i == 0 && r.Octal
+		if !r.Octal {
+			special = i%3 == 0 && strings.ContainsRune("sStT", c)
+		}
+		if special {
+			if i > start {
+				b.WriteString(st.Render(perm[start:i]))
+			}
+			b.WriteString(specialStyle.Render(perm[i : i+1]))
+			start = i + 1
+		}
+	}
+	return b.String() + st.Render(sub[start:])
+}
+
+// perm is the permissions field of e's long-mode subtitle.
+func (r PanelRenderer) perm(e Entry) string {
+	if r.Octal {
+		return octalPerm(e.Mode)
+	}
+	return permString(e.Mode)
+}
@@ -0,0 +1,156 @@
+package peek
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// Only this much of a file is read when previewing it
+const previewBytes = 4096
+
+// PreviewFile returns the first n lines of the text file name in dir,
+// or nil when it is unreadable or looks binary.
+func (s *Scanner) PreviewFile(dir, name string, n int) []string {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil
+	}
+	return previewLines(fsys, path.Join(root, name), n)
+}
+
+// previewLines returns the first n lines of a text file, or nil when
+// the file is unreadable or looks binary: a NUL byte or invalid UTF-8
+// in its first previewBytes.
+func previewLines(fsys fs.FS, name string, n int) []string {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	buf := make([]byte, previewBytes)
+	size, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil
+	}
+	buf = buf[:size]
+	if bytes.IndexByte(buf, 0) >= 0 || !validPrefix(buf, size == previewBytes) {
+		return nil
+	}
+
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(buf))
+	for len(lines) < n && sc.Scan() {
+		lines = append(lines, strings.ReplaceAll(strings.TrimRight(sc.Text(), "\r"), "\t", "    "))
+	}
+	return lines
+}
+
+// countLines counts the lines of a text file, false when it is
+// unreadable or its first previewBytes look binary. A last line without
+// a newline counts.
+func countLines(fsys fs.FS, name string) (int, bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 32*1024)
+	lines, last, first := 0, byte('\n'), true
+	for {
+		n, err := io.ReadFull(f, buf)
+		if first {
+			head := buf[:min(n, previewBytes)]
+			if bytes.IndexByte(head, 0) >= 0 || !validPrefix(head, n > previewBytes) {
+				return 0, false
+			}
+			first = false
+		}
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return 0, false
+		}
+	}
+	if last != '\n' {
+		lines++
+	}
+	return lines, true
+}
+
+// validPrefix checks buf is UTF-8, allowing a rune cut off at the end
+// when the read stopped short of the file's end.
+func validPrefix(buf []byte, cut bool) bool {
+	if utf8.Valid(buf) {
+		return true
+	}
+	if !cut {
+		return false
+	}
+	for i := 1; i < utf8.UTFMax && i <= len(buf); i++ {
+		if utf8.Valid(buf[:len(buf)-i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// readmeExts are the extensions a README is looked for with, best first.
+var readmeExts = []string{"md", "markdown", "rst", "txt", "org", "adoc", ""}
+
+// Readme returns the README among a dir's entries, README.md or
+// readme.txt and such, preferring Markdown when there are several.
+func Readme(entries []Entry) (Entry, bool) {
+	best, rank := Entry{}, len(readmeExts)
+	for _, e := range entries {
+		if e.IsDir {
+			continue
+		}
+		stem, ext, _ := strings.Cut(strings.ToLower(e.Name), ".")
+		if stem != "readme" {
+			continue
+		}
+		for i, x := range readmeExts[:rank] {
+			if ext == x {
+				best, rank = e, i
+				break
+			}
+		}
+	}
+	return best, rank < len(readmeExts)
+}
+
+// ReadmePreview returns the name of the README among the entries of
+// dir and its first n lines with words in them: blank lines, badges and
+// HTML tags are passed over. The name is "" when there is none.
+func (s *Scanner) ReadmePreview(dir string, entries []Entry, n int) (string, []string) {
+	e, ok := Readme(entries)
+	if !ok {
+		return "", nil
+	}
+	var lines []string
+	for _, l := range s.PreviewFile(dir, e.Name, 64) {
+		t := strings.TrimSpace(l)
+		if t == "" || strings.HasPrefix(t, "[![") || strings.HasPrefix(t, "![") || strings.HasPrefix(t, "<") {
+			continue
+		}
+		if lines = append(lines, Sanitize(l)); len(lines) == n {
+			break
+		}
+	}
+	if lines == nil {
+		return "", nil
+	}
+	return e.Name, lines
+}
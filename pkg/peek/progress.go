@@ -0,0 +1,22 @@
+package peek
+
+import "sync/atomic"
+
+// Progress counts the entries Scans have read so far, including those
+// read for child counts and sizes, so a caller can report on a slow
+// scan while it runs. It is safe to read while Scans add to it.
+type Progress struct {
+	n atomic.Int64
+}
+
+// Entries is how many entries have been read.
+func (p *Progress) Entries() int64 {
+	return p.n.Load()
+}
+
+// add counts n more entries. A nil Progress ignores them.
+func (p *Progress) add(n int) {
+	if p != nil {
+		p.n.Add(int64(n))
+	}
+}
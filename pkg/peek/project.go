@@ -0,0 +1,90 @@
+package peek
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Project is what the manifests in a dir say about it.
+type Project struct {
+	Kinds []string // one per tool found, e.g. "go", "node", "make"
+
+	// From go.mod's module line, else package.json; "" when neither
+	// names the package
+	Name, Version string
+}
+
+// The manifests Project looks for, in badge order
+var manifests = []struct{ file, kind string }{
+	{"go.mod", "go"},
+	{"package.json", "node"},
+	{"Cargo.toml", "rust"},
+	{"pyproject.toml", "python"},
+	{"Makefile", "make"},
+	{"GNUmakefile", "make"},
+	{"makefile", "make"},
+}
+
+// Project reports the kinds of project dir holds, judged by the
+// manifest files at its top, and the package it declares. Unreadable
+// paths give an empty Project.
+func (s *Scanner) Project(dir string) Project {
+	var p Project
+	fsys, name, err := s.resolve(dir)
+	if err != nil {
+		return p
+	}
+	for _, m := range manifests {
+		info, err := fs.Stat(fsys, path.Join(name, m.file))
+		if err != nil || info.IsDir() || slices.Contains(p.Kinds, m.kind) {
+			continue
+		}
+		p.Kinds = append(p.Kinds, m.kind)
+	}
+	if data, err := fs.ReadFile(fsys, path.Join(name, "go.mod")); err == nil {
+		p.Name = goModule(string(data))
+	}
+	if data, err := fs.ReadFile(fsys, path.Join(name, "package.json")); err == nil && p.Name == "" {
+		var pkg struct{ Name, Version string }
+		if json.Unmarshal(data, &pkg) == nil {
+			p.Name, p.Version = pkg.Name, pkg.Version
+		}
+	}
+	return p
+}
+
+// goModule is the module path declared in go.mod source src.
+func goModule(src string) string {
+	for _, line := range strings.Split(src, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`+"`")
+		}
+	}
+	return ""
+}
+
+// Package names longer than this are cut in the title
+const maxPackageWidth = 48
+
+// ProjectBadges renders p's kinds as "[go] [make]" for beside a
+// listing's title, then its package's name and version, or "" for a
+// plain dir.
+func ProjectBadges(p Project) string {
+	badges := make([]string, len(p.Kinds))
+	for i, k := range p.Kinds {
+		badges[i] = sepStyle.Render("[") + metaStyle.Render(k) + sepStyle.Render("]")
+	}
+	out := strings.Join(badges, " ")
+	if p.Name != "" {
+		pkg := fileNameStyle.Render(Truncate(p.Name, maxPackageWidth))
+		if p.Version != "" {
+			pkg += " " + CountStyle.Render(Truncate(p.Version, 16))
+		}
+		out = strings.TrimPrefix(out+"  "+pkg, "  ")
+	}
+	return out
+}
@@ -0,0 +1,628 @@
+package peek
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const maxNameLen = 80
+
+// AutoColumns as PanelRenderer.Columns picks the column count by width.
+const AutoColumns = -1
+
+const (
+	panelGap     = 2   // between the DIRS and FILES boxes
+	stackWidth   = 60  // narrower terminals stack the boxes
+	longWidth    = 140 // and in long mode, where details need the room
+	stackChrome  = 6   // border, padding and header of the lower box
+	gridGap      = 3   // between grid columns
+	minCellWidth = 24  // narrowest grid column
+)
+
+// Renderer writes a scanned listing to w.
+type Renderer interface {
+	Render(w io.Writer, entries []Entry) error
+}
+
+// PanelRenderer draws the DIRS and FILES boxes followed by a count footer.
+type PanelRenderer struct {
+	Width int     // terminal columns; 80 when zero
+	Icons IconSet // glyph before each name
+	Long  bool    // detail subtitles: mode, owner, mtime, size
+	Octal bool    // long-mode permissions as 755 rather than rwxr-xr-x
+	Times bool    // relative mtimes ("3h ago") in subtitles
+
+	// Classify marks executable files with a trailing "*", as ls -F does
+	Classify bool
+
+	// Bare draws names alone, without subtitles or leaders, for the
+	// entries of a Scanner.Fast scan.
+	Bare bool
+
+	// Colors, when set, styles names by LS_COLORS rules instead of the theme
+	Colors *LSColors
+
+	// LinkDir, when set, is the absolute dir being listed; names become
+	// OSC 8 hyperlinks to their files.
+	LinkDir string
+
+	// GroupKinds splits FILES into sections by Kind: code, images,
+	// documents, archives, media and other.
+	GroupKinds bool
+
+	// MaxRows, when set, caps the rows of each panel in Render, or of
+	// both together when they are stacked; the entries past it are
+	// summed up as "… and 37 more files", followed by MoreHint if it fits.
+	MaxRows  int
+	MoreHint string
+
+	// Columns lays each panel out as a grid of that many columns, filled
+	// down then across as ls -C does; AutoColumns fits as many as the
+	// widest entry allows. 0 or 1 is the one-entry-per-line list. Grids
+	// leave out preview lines.
+	Columns int
+
+	// Hidden is how many hidden entries the scan left out; see
+	// Scanner.ScanHidden. The footer counts them.
+	Hidden int
+
+	moreDirs, moreFiles int // entries cut by MaxRows
+}
+
+func (r PanelRenderer) Render(w io.Writer, entries []Entry) error {
+	dirs, files := Split(entries)
+	if len(dirs) == 0 && len(files) == 0 {
+		_, err := fmt.Fprintln(w, r.Footer(nil, nil))
+		return err
+	}
+
+	footer := r.Footer(dirs, files)
+	if r.MaxRows > 0 {
+		width := r.contentWidth(r.sideBySide(dirs, files))
+		dirCols, _ := r.layout(dirs, width)
+		fileCols, _ := r.layout(files, width)
+		dirRows, fileRows := r.MaxRows, r.MaxRows
+		if r.Stacked() && len(dirs) > 0 && len(files) > 0 {
+			// Dirs take what they need up to half, files the rest
+			avail := r.MaxRows - stackChrome
+			dirRows = max(min((len(dirs)+dirCols-1)/dirCols, avail/2), 3)
+			fileRows = max(avail-dirRows, 3)
+		}
+		dirs, r.moreDirs = clipRows(dirs, dirRows, dirCols)
+		files, r.moreFiles = clipRows(files, fileRows, fileCols)
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n\n%s\n\n", r.Panels(dirs, files, -1), footer)
+	return err
+}
+
+// clipRows keeps the leading entries that fit in rows of cols entries,
+// counting preview lines in a list and saving the last row for the
+// summary, and says how many were dropped.
+func clipRows(entries []Entry, rows, cols int) ([]Entry, int) {
+	if cols > 1 {
+		if len(entries) <= rows*cols {
+			return entries, 0
+		}
+		keep := max(rows-1, 1) * cols
+		return entries[:keep], len(entries) - keep
+	}
+	used := 0
+	for i, e := range entries {
+		used += 1 + len(e.Preview)
+		if used > rows || used == rows && i < len(entries)-1 {
+			return entries[:i], len(entries) - i
+		}
+	}
+	return entries, 0
+}
+
+// more is the summary line for n entries cut from a panel.
+func (r PanelRenderer) more(n int, noun string, lineWidth int) string {
+	s := "… and " + Plural(n, "more "+noun)
+	if r.MoreHint != "" && textWidth(s)+1+textWidth(r.MoreHint) <= lineWidth {
+		s += " " + r.MoreHint
+	}
+	return CountStyle.Render(s)
+}
+
+// Panels lays out the DIRS and FILES boxes side by side, one above the
+// other on narrow terminals, or a single wide box when one of them is
+// empty. sel highlights one entry,
+// counting dirs first then files; -1 for none.
+func (r PanelRenderer) Panels(dirs, files []Entry, sel int) string {
+	dirSel, fileSel := sel, -1
+	if sel >= len(dirs) {
+		dirSel, fileSel = -1, sel-len(dirs)
+	}
+
+	both := r.sideBySide(dirs, files)
+	width := r.contentWidth(both)
+	box := r.box(width)
+	if !both {
+		// Single panel modes, or both stacked
+		var boxes []string
+		if len(dirs) > 0 {
+			boxes = append(boxes, box.Render(r.dirContent(dirs, width, dirSel)))
+		}
+		if len(files) > 0 || len(dirs) == 0 {
+			boxes = append(boxes, box.Render(r.fileContent(files, width, fileSel)))
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, boxes...)
+	}
+
+	// Two panels side by side
+	leftPanel := box.Render(r.dirContent(dirs, width, dirSel))
+	rightPanel := box.Render(r.fileContent(files, width, fileSel))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, strings.Repeat(" ", panelGap), rightPanel)
+}
+
+// Stacked reports whether Panels puts DIRS above FILES rather than
+// beside them, as it does when Width is under 60 columns, or in long
+// mode under 140 (or unset), as two panels leave no room for details.
+func (r PanelRenderer) Stacked() bool {
+	if r.Long {
+		return r.Width < longWidth
+	}
+	return r.Width > 0 && r.Width < stackWidth
+}
+
+// sideBySide reports whether dirs and files get a box each, side by side.
+func (r PanelRenderer) sideBySide(dirs, files []Entry) bool {
+	return len(dirs) > 0 && len(files) > 0 && !r.Stacked()
+}
+
+// contentWidth is the room inside a panel's border and padding, for one
+// full-width box or for each of two side by side.
+func (r PanelRenderer) contentWidth(both bool) int {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	inner := width - 2 // full width minus border
+	if both {
+		inner = (width-panelGap)/2 - 2
+	}
+	return max(inner, 20) - 4
+}
+
+// layout picks the columns for entries in a panel width wide, and the
+// width of each.
+func (r PanelRenderer) layout(entries []Entry, width int) (cols, cellW int) {
+	cols = r.Columns
+	if cols == AutoColumns {
+		widest := 0
+		for _, e := range entries {
+			widest = max(widest, r.naturalWidth(e))
+		}
+		cols = (width + gridGap) / (min(widest, maxNameLen) + gridGap)
+	}
+	cols = min(cols, (width+gridGap)/(minCellWidth+gridGap))
+	if cols <= 1 {
+		return 1, min(width, maxNameLen)
+	}
+	return cols, min((width-(cols-1)*gridGap)/cols, maxNameLen)
+}
+
+// naturalWidth is how wide e's line is with its name in full.
+func (r PanelRenderer) naturalWidth(e Entry) int {
+	w := 2
+	if icon := r.Icons.Icon(e); icon != "" {
+		w = textWidth(icon) + 1
+	}
+	w += textWidth(Sanitize(e.Name))
+	if !r.Bare {
+		w += 3 + textWidth(r.meta(e, sizeLabel(e), maxNameLen))
+	}
+	if r.Classify && e.Executable() {
+		w++
+	}
+	return w
+}
+
+// grid arranges cells of cellW into cols columns, down then across.
+func grid(cells []string, cols, cellW int) []string {
+	if cols <= 1 {
+		return cells
+	}
+	rows := (len(cells) + cols - 1) / cols
+	lines := make([]string, rows)
+	for i, c := range cells {
+		row := i % rows
+		if i >= rows {
+			lines[row] += strings.Repeat(" ", gridGap)
+		}
+		if i+rows < len(cells) {
+			c += strings.Repeat(" ", max(cellW-textWidth(c), 0))
+		}
+		lines[row] += c
+	}
+	return lines
+}
+
+// gridWidth is the width of a row of cols cells.
+func gridWidth(cols, cellW int) int {
+	return cols*cellW + (cols-1)*gridGap
+}
+
+func makeHeader(title string, lineWidth int) string {
+	line := sepStyle.Render(strings.Repeat("─", lineWidth))
+	return TitleStyle.Render(title) + "\n" + line + "\n"
+}
+
+// dirContent is the DIRS header and entries for a panel width wide.
+func (r PanelRenderer) dirContent(dirs []Entry, width int, sel int) string {
+	cols, cellW := r.layout(dirs, width)
+	cells := make([]string, len(dirs))
+	for i, d := range dirs {
+		cells[i] = r.dirLine(d, cellW, i == sel)
+	}
+	lines := grid(cells, cols, cellW)
+	if r.moreDirs > 0 {
+		lines = append(lines, r.more(r.moreDirs, "dir", gridWidth(cols, cellW)))
+	}
+	return makeHeader("DIRS", gridWidth(cols, cellW)) + strings.Join(lines, "\n")
+}
+
+// dirLine renders one dir.
+func (r PanelRenderer) dirLine(d Entry, lineWidth int, selected bool) string {
+	prefix := dirIndicator.Render("▸") + " "
+	prefixW := 2
+	if icon := r.Icons.Icon(d); icon != "" {
+		prefix = dirIndicator.Render(icon) + " "
+		prefixW = textWidth(icon) + 1
+	}
+	if d.Pinned {
+		prefix = pinMark(prefixW)
+	}
+	sub := ""
+	if !r.Bare {
+		room := lineWidth - prefixW - 3
+		if d.About != "" {
+			// A description gives way to the name, down to half the line
+			rest := textWidth(subtitle(d)) - textWidth(d.About)
+			d.About = Truncate(d.About, room-min(textWidth(Sanitize(d.Name)), room/2)-rest)
+		}
+		sub = r.meta(d, subtitle(d), room)
+	}
+	nameLimit := lineWidth - textWidth(sub) - prefixW - 3
+	if nameLimit < 8 {
+		nameLimit = 8
+	}
+	name := Truncate(d.Name, nameLimit)
+
+	var styledName string
+	metaStyled := metaStyle
+	lsStyle, lsOK := r.Colors.Style(d)
+	switch {
+	case selected:
+		styledName = cursorStyle.Render(name)
+	case d.Flagged:
+		styledName, metaStyled = ErrStyle.Render(name), ErrStyle
+	case d.Ignored || d.Pruned:
+		styledName = dotDirStyle.Render(name)
+	case lsOK:
+		styledName = lsStyle.Render(name)
+	case d.IsSymlink:
+		styledName = symNameStyle.Render(name)
+	case d.Hidden:
+		styledName = dotDirStyle.Render(name)
+	default:
+		styledName = dirNameStyle.Render(name)
+	}
+
+	dots := lineWidth - textWidth(name) - textWidth(sub) - prefixW
+	if dots < 3 {
+		dots = 3
+	}
+	leader := " " + dotLeaderStyle.Render(strings.Repeat("·", dots-2)) + " "
+	if r.Bare {
+		leader = ""
+	}
+	return prefix + hyperlink(r.LinkDir, d.Name, styledName) + leader + r.styleMeta(metaStyled, sub, d)
+}
+
+// fileContent is the FILES header and entries for a panel width wide.
+func (r PanelRenderer) fileContent(files []Entry, width int, sel int) string {
+	cols, cellW := r.layout(files, width)
+	entryLines := func(idx []int) []string {
+		var lines, cells []string
+		for _, i := range idx {
+			fl := r.fileLines(files[i], cellW, i == sel)
+			if cols > 1 {
+				cells = append(cells, fl[0])
+			} else {
+				lines = append(lines, fl...)
+			}
+		}
+		return append(lines, grid(cells, cols, cellW)...)
+	}
+
+	var lines []string
+	if r.GroupKinds {
+		for _, g := range kindGroups {
+			var idx []int
+			for i, f := range files {
+				if groupOf(KindOf(f)) == g.name {
+					idx = append(idx, i)
+				}
+			}
+			if len(idx) == 0 {
+				continue
+			}
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, CountStyle.Render(fmt.Sprintf("%s (%d)", g.name, len(idx))))
+			lines = append(lines, entryLines(idx)...)
+		}
+		if r.moreFiles > 0 {
+			lines = append(lines, "")
+		}
+	} else {
+		idx := make([]int, len(files))
+		for i := range idx {
+			idx[i] = i
+		}
+		lines = entryLines(idx)
+	}
+	if r.moreFiles > 0 {
+		lines = append(lines, r.more(r.moreFiles, "file", gridWidth(cols, cellW)))
+	}
+	return makeHeader("FILES", gridWidth(cols, cellW)) + strings.Join(lines, "\n")
+}
+
+// Sections of the FILES panel when grouping by kind, in display order
+var kindGroups = []struct {
+	name  string
+	kinds []Kind
+}{
+	{"code", []Kind{KindCode, KindData}},
+	{"images", []Kind{KindImage}},
+	{"documents", []Kind{KindDocument}},
+	{"archives", []Kind{KindArchive}},
+	{"media", []Kind{KindAudio, KindVideo}},
+	{"other", []Kind{KindOther}},
+}
+
+func groupOf(k Kind) string {
+	for _, g := range kindGroups {
+		for _, gk := range g.kinds {
+			if gk == k {
+				return g.name
+			}
+		}
+	}
+	return "other"
+}
+
+// fileLines renders one file and its preview lines, if any.
+func (r PanelRenderer) fileLines(f Entry, lineWidth int, selected bool) []string {
+	// 2 chars for prefix space alignment with dir panel
+	prefix := "  "
+	prefixW := 2
+	if icon := r.Icons.Icon(f); icon != "" {
+		prefix = metaStyle.Render(icon) + " "
+		prefixW = textWidth(icon) + 1
+	}
+	if f.Pinned {
+		prefix = pinMark(prefixW)
+	}
+	sz := ""
+	if !r.Bare {
+		sz = r.meta(f, sizeLabel(f), lineWidth-prefixW-3)
+	}
+	nameLimit := lineWidth - textWidth(sz) - prefixW - 3
+	if nameLimit < 8 {
+		nameLimit = 8
+	}
+	name := classified(f, nameLimit, r.Classify)
+
+	var styledName string
+	metaStyled := metaStyle
+	lsStyle, lsOK := r.Colors.Style(f)
+	switch {
+	case selected:
+		styledName = cursorStyle.Render(name)
+	case f.Flagged:
+		styledName, metaStyled = ErrStyle.Render(name), ErrStyle
+	case f.Ignored:
+		styledName = dotFileStyle.Render(name)
+	case lsOK:
+		styledName = lsStyle.Render(name)
+	case f.IsSymlink:
+		styledName = symNameStyle.Render(name)
+	case f.Executable():
+		styledName = execNameStyle.Render(name)
+	case f.Binary:
+		styledName = binNameStyle.Render(name)
+	case f.Hidden:
+		styledName = dotFileStyle.Render(name)
+	default:
+		styledName = fileNameStyle.Render(name)
+	}
+
+	dots := lineWidth - textWidth(name) - textWidth(sz) - prefixW
+	if dots < 3 {
+		dots = 3
+	}
+	leader := " " + dotLeaderStyle.Render(strings.Repeat("·", dots-2)) + " "
+	if r.Bare {
+		leader = ""
+	}
+	lines := []string{prefix + hyperlink(r.LinkDir, f.Name, styledName) + leader + r.styleMeta(metaStyled, sz, f)}
+	for _, l := range f.Preview {
+		if l != "" {
+			l = Truncate(l, lineWidth-prefixW)
+		}
+		lines = append(lines, strings.Repeat(" ", prefixW)+previewStyle.Render(l))
+	}
+	return lines
+}
+
+// pinMark stands in for the prefix of a pinned entry, width cells of it.
+func pinMark(width int) string {
+	return TitleStyle.Render("•") + strings.Repeat(" ", width-1)
+}
+
+// classified truncates e's name to limit cells, ending it in "*" when
+// mark is set and e is executable.
+func classified(e Entry, limit int, mark bool) string {
+	if mark && e.Executable() {
+		return Truncate(e.Name, limit-1) + "*"
+	}
+	return Truncate(e.Name, limit)
+}
+
+// Names keep at least this many cells before long-mode details are shed
+const minNameRoom = 12
+
+// meta is the subtitle after a name: base, led by e.Note when set, or
+// in long mode the detail line "mode owner group mtime base". Group,
+// then owner, are dropped while the name would be left fewer than
+// minNameRoom of room cells; mode and mtime always stay, cutting the
+// name short if need be. Symlinks end in "→ target",
+// shortened or left out as room runs low.
+func (r PanelRenderer) meta(e Entry, base string, room int) string {
+	if e.Note != "" {
+		base = e.Note + "  " + base
+	}
+	return withTarget(e, r.details(e, base, room), room)
+}
+
+func (r PanelRenderer) details(e Entry, base string, room int) string {
+	if !r.Long {
+		if r.Times && !e.ModTime.IsZero() {
+			base += "  " + RelTime(e.ModTime)
+		}
+		return base
+	}
+	mode, when := r.perm(e), detailTime(e.ModTime)
+	if r.Times && !e.ModTime.IsZero() {
+		when = RelTime(e.ModTime)
+	}
+	candidates := [][]string{
+		{mode, e.Owner, e.Group, when, base},
+		{mode, e.Owner, when, base},
+	}
+	for _, fields := range candidates {
+		line := joinFields(fields)
+		if textWidth(line) <= room-minNameRoom {
+			return line
+		}
+	}
+	// Mode and mtime stay however narrow it gets: the name is cut instead
+	return joinFields([]string{mode, when, base})
+}
+
+// withTarget appends e's symlink target to sub if at least a few cells
+// of it fit in room.
+func withTarget(e Entry, sub string, room int) string {
+	if !e.IsSymlink || e.Target == "" {
+		return sub
+	}
+	avail := room - minNameRoom - textWidth(sub) - 4
+	if avail < 4 {
+		return sub
+	}
+	return sub + "  → " + Truncate(e.Target, avail)
+}
+
+func joinFields(fields []string) string {
+	var kept []string
+	for _, f := range fields {
+		if f != "" {
+			kept = append(kept, f)
+		}
+	}
+	return strings.Join(kept, "  ")
+}
+
+// detailTime formats like ls: clock time within six months, else the year.
+func detailTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if time.Since(t) < 180*24*time.Hour && t.Before(time.Now().Add(time.Hour)) {
+		return t.Format("Jan _2 15:04")
+	}
+	return t.Format("Jan _2  2006")
+}
+
+// RelTime describes t relative to now: "just now", "5m ago", "3h ago"
+// and "12d ago" within a month, the date beyond that or in the future.
+func RelTime(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < 0 || d >= 30*24*time.Hour:
+		return t.Format("2006-01-02")
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+// Footer is the line under the panels: the counts of dirs and files,
+// the files' total size, the whole listing's once dirs are sized, and
+// how many entries are hidden.
+func (r PanelRenderer) Footer(dirs, files []Entry) string {
+	var parts []string
+	if len(dirs) == 0 && len(files) == 0 {
+		parts = append(parts, "empty")
+	} else {
+		parts = append(parts, footerText(len(dirs), len(files)))
+	}
+	var size int64
+	for _, f := range files {
+		size += f.Size
+	}
+	if len(files) > 0 && !r.Bare {
+		parts = append(parts, HumanSize(size)+" in files")
+	}
+	sized := false
+	for _, d := range dirs {
+		if d.DirSized {
+			sized = true
+			size += d.DirSize
+		}
+	}
+	if sized {
+		parts = append(parts, HumanSize(size)+" in all")
+	}
+	if r.Hidden > 0 {
+		parts = append(parts, fmt.Sprintf("%d hidden", r.Hidden))
+	}
+	return "  " + CountStyle.Render(strings.Join(parts, "  ·  "))
+}
+
+// Footer summarises the listing, e.g. "3 dirs  ·  1 file".
+func Footer(dirCount, fileCount int) string {
+	return "  " + CountStyle.Render(footerText(dirCount, fileCount))
+}
+
+func footerText(dirCount, fileCount int) string {
+	parts := []string{}
+	if dirCount > 0 {
+		parts = append(parts, Plural(dirCount, "dir"))
+	}
+	if fileCount > 0 {
+		parts = append(parts, Plural(fileCount, "file"))
+	}
+	return strings.Join(parts, "  ·  ")
+}
+
+// Plural counts n of noun, "1 file" or "3 files".
+func Plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,151 @@
+package peek
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/name, or rewrites it with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	file := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs:\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+func renderEntries() []Entry {
+	return []Entry{
+		{Name: "src", IsDir: true, SubDirs: 1, SubFiles: 12, DirSize: 48213, DirSized: true, About: "the code"},
+		{Name: "vendor", IsDir: true, Unavailable: true},
+		{Name: "main.go", Ext: "go", Size: 2048, Lines: 80, Counted: true, Sniffed: true, Detected: "Go"},
+		{Name: "logo, large.png", Ext: "png", Size: 51200, Width: 640, Height: 480, Hidden: true},
+		{Name: "latest", IsSymlink: true, Target: "releases/v1.2.0"},
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	for _, tt := range []struct {
+		golden string
+		r      JSONRenderer
+	}{
+		{"entries.json", JSONRenderer{}},
+		{"entries-path.json", JSONRenderer{Path: "/srv/app"}},
+	} {
+		var b bytes.Buffer
+		if err := tt.r.Render(&b, renderEntries()); err != nil {
+			t.Fatal(err)
+		}
+		golden(t, tt.golden, b.Bytes())
+	}
+}
+
+func TestCSVRenderer(t *testing.T) {
+	for _, tt := range []struct {
+		golden string
+		r      CSVRenderer
+	}{
+		{"entries.csv", CSVRenderer{}},
+		{"entries.tsv", CSVRenderer{Comma: '\t'}},
+	} {
+		var b bytes.Buffer
+		if err := tt.r.Render(&b, renderEntries()); err != nil {
+			t.Fatal(err)
+		}
+		golden(t, tt.golden, b.Bytes())
+	}
+}
+
+func TestMarkdownCells(t *testing.T) {
+	entries := []Entry{
+		{Name: "two\nlines", IsDir: true, About: "first | second\r\nthird"},
+		{Name: "a_b*c.txt", Size: 10},
+	}
+	var b bytes.Buffer
+	if err := (MarkdownRenderer{}).Render(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"| two lines/ | first \\| second third |\n",
+		"| a\\_b\\*c.txt | 10 B |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	// Header, separator and one row per entry in each table, footer
+	if rows := strings.Count(out, "\n"); rows != 9 {
+		t.Errorf("%d lines, want 9:\n%s", rows, out)
+	}
+}
+
+func TestCodeFooter(t *testing.T) {
+	tests := []struct {
+		stat LangStat
+		want string
+	}{
+		{LangStat{Lang: "Go", Files: 1, Lines: 1, Code: 1}, "1 file  ·  1 line  ·  1 code"},
+		{LangStat{Lang: "Go", Files: 2, Lines: 10, Code: 8, Blanks: 2}, "2 files  ·  10 lines  ·  8 code"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := (CodeRenderer{}).Render(&b, []LangStat{tt.stat}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(b.String(), tt.want) {
+			t.Errorf("footer for %+v lacks %q:\n%s", tt.stat, tt.want, b.String())
+		}
+	}
+}
+
+func TestFooterText(t *testing.T) {
+	tests := []struct {
+		dirs, files int
+		want        string
+	}{
+		{0, 0, ""},
+		{1, 0, "1 dir"},
+		{0, 1, "1 file"},
+		{3, 2, "3 dirs  ·  2 files"},
+	}
+	for _, tt := range tests {
+		if got := footerText(tt.dirs, tt.files); got != tt.want {
+			t.Errorf("footerText(%d, %d) = %q, want %q", tt.dirs, tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownTitle(t *testing.T) {
+	tests := []struct{ title, want string }{
+		{"/srv/app", "## `/srv/app`\n"},
+		{"it's `x`", "## `` it's `x` ``\n"},
+		{"a``b", "## ```a``b```\n"},
+		{"two\nlines", "## `two lines`\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := (MarkdownRenderer{Title: tt.title}).Render(&b, nil); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(b.String(), tt.want) {
+			t.Errorf("title %q: got %q, want heading %q", tt.title, b.String(), tt.want)
+		}
+	}
+}
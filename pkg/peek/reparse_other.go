@@ -0,0 +1,11 @@
+//go:build !windows
+
+package peek
+
+import "io/fs"
+
+// reparseLink reports Windows junctions and other link-like reparse
+// points; elsewhere symlinks are the only links.
+func reparseLink(fs.FS, string, fs.FileInfo) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,83 @@
+package peek
+
+import (
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+
+	"golang.org/x/sys/windows"
+)
+
+// Not defined by x/sys/windows; the reparse tag of the zero-byte
+// "execution aliases" in WindowsApps, such as python.exe and winget.exe.
+const ioReparseTagAppExecLink = 0x8000001B
+
+// reparseLink reports whether name is a junction, mount point or app
+// execution alias, which Go lists as irregular files or dirs rather than
+// symlinks, and returns its target. Other reparse points, such as
+// deduplicated or cloud placeholder files, are ordinary entries.
+func reparseLink(fsys fs.FS, name string, info fs.FileInfo) (string, bool) {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok || attrs.FileAttributes&syscall.FILE_ATTRIBUTE_REPARSE_POINT == 0 {
+		return "", false
+	}
+	p, ok := osPath(fsys, name)
+	if !ok {
+		return "", false
+	}
+	buf, err := reparseData(p)
+	if err != nil || len(buf) < 8 {
+		return "", false
+	}
+	switch binary.LittleEndian.Uint32(buf) {
+	case windows.IO_REPARSE_TAG_MOUNT_POINT, windows.IO_REPARSE_TAG_SYMLINK:
+		target, _ := os.Readlink(p)
+		return target, true
+	case ioReparseTagAppExecLink:
+		return appExecTarget(buf[8:]), true
+	}
+	return "", false
+}
+
+// reparseData reads the reparse buffer of the link at p itself.
+func reparseData(p string) ([]byte, error) {
+	name, err := windows.UTF16PtrFromString(p)
+	if err != nil {
+		return nil, err
+	}
+	h, err := windows.CreateFile(name, 0,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		windows.OPEN_EXISTING, windows.FILE_FLAG_OPEN_REPARSE_POINT|windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(h)
+	buf := make([]byte, windows.MAXIMUM_REPARSE_DATA_BUFFER_SIZE)
+	var n uint32
+	if err := windows.DeviceIoControl(h, windows.FSCTL_GET_REPARSE_POINT, nil, 0, &buf[0], uint32(len(buf)), &n, nil); err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// appExecTarget extracts the executable path from an app execution
+// alias: a version number followed by NUL-terminated UTF-16 strings for
+// the package, the app and then the target.
+func appExecTarget(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	data = data[4:]
+	u := make([]uint16, len(data)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	fields := strings.Split(string(utf16.Decode(u)), "\x00")
+	if len(fields) < 3 {
+		return ""
+	}
+	return fields[2]
+}
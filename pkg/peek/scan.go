@@ -0,0 +1,690 @@
+package peek
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scanner lists the immediate contents of a directory.
+type Scanner struct {
+	// FS is the filesystem to list, with slash-separated paths as
+	// fs.FS expects. When nil, paths are OS paths and archives along
+	// them are opened transparently.
+	FS fs.FS
+
+	ShowAll    bool // include dotfiles and, on Windows, hidden files
+	FilesOnly  bool // drop directories from the result
+	DiskUsage  bool // compute recursive directory sizes
+	Workers    int  // concurrent size walks; NumCPU when zero
+	Sort       SortKey
+	Match      []string       // keep only files matching one of these globs
+	Owners     bool           // look up owner and group names, for detail mode
+	Preview    int            // leading lines of text files to keep; 0 for none
+	Lines      bool           // count the lines of text files
+	Sniff      bool           // read the head of each file for Binary and Detected
+	TextOnly   bool           // drop binary files; implies Sniff
+	Dimensions bool           // read the width and height of images
+	Media      bool           // read durations and codecs of audio and video, photos' EXIF dates, PDF page counts
+	GitIgnore  bool           // drop entries matched by .gitignore rules
+	Regex      *regexp.Regexp // keep only dirs and files whose name matches
+	Follow     bool           // count and size through symlinked dirs
+	SizeCache  *SizeCache     // reuse dir sizes from earlier runs, locally
+
+	// Prune names heavy dirs, such as node_modules, that are listed
+	// but not read: they get no child counts, trees don't descend into
+	// them, and only DiskUsage still walks them for a size.
+	Prune []string
+
+	// MarkIgnored keeps the entries GitIgnore would drop, inside a git
+	// repository, and sets their Ignored.
+	MarkIgnored bool
+
+	// MaxChildren stops counting a dir's children once it has found
+	// this many, marking it SubCapped; 0 counts them all.
+	MaxChildren int
+
+	// Fast lists names and types from the dir listing alone: no stat,
+	// link targets or child counts, so entries carry no size, mtime or
+	// permissions and the size and time filters don't apply.
+	Fast bool
+
+	// Timing, when set, totals the time spent per Phase of each Scan.
+	Timing *Timing
+
+	// Progress, when set, counts the entries read as Scans run.
+	Progress *Progress
+
+	// Timeout, when non-zero, abandons calls into the OS filesystem
+	// that take longer, as on a dead network mount. Entries that time
+	// out are kept as Unavailable; a dir that can't be read fails with
+	// ErrTimeout.
+	Timeout time.Duration
+
+	// Errors, when set, collects what couldn't be read below the
+	// listed dir; otherwise those entries are silently left out.
+	Errors *ScanErrors
+
+	// Annotate, when set, is called for each listed entry with its path
+	// (an OS path unless FS is set) and returns a short note shown in
+	// its subtitle. Calls run concurrently, Workers at a time.
+	Annotate func(path string, e Entry) string
+
+	// Pinned, when set, is called for each listed entry with its path as
+	// for Annotate; the entries it reports are marked Pinned and put at
+	// the top of their panel, whatever the sort.
+	Pinned func(path string, e Entry) bool
+
+	// Keep only entries modified after NewerThan and before OlderThan;
+	// zero times set no bound.
+	NewerThan, OlderThan time.Time
+
+	// Keep only files of at least MinSize and, when non-zero, at most
+	// MaxSize bytes. Dirs are unaffected.
+	MinSize, MaxSize int64
+}
+
+// CommonPrune is the usual heavy dirs for Scanner.Prune: dependencies,
+// build output, caches and VCS data.
+var CommonPrune = []string{"node_modules", ".git", "target", "dist", "__pycache__", ".venv"}
+
+// Scan reads dir and returns its dirs followed by its files, each
+// ordered by s.Sort: by default dirs by name and files by decreasing size.
+//
+// On the OS filesystem, archives (.zip, .tar, .tar.gz, ...) are listed
+// as directories, and a path running through one, such as
+// "dist.zip/bin", lists that member. Absolute symlink targets inside
+// dir are reported relative to it.
+func (s *Scanner) Scan(dir string) ([]Entry, error) {
+	entries, _, err := s.ScanHidden(dir)
+	return entries, err
+}
+
+// ScanHidden is Scan, also counting the hidden entries left out for
+// want of ShowAll.
+func (s *Scanner) ScanHidden(dir string) ([]Entry, int, error) {
+	fsys, name, err := s.resolve(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries, hiddenCount, err := s.scanFS(fsys, name, s.ignores(dir, fsys, name))
+	// os.DirFS reports paths relative to its root; name the real one
+	if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+		pe.Path = dir
+	}
+	if _, _, inArchive := splitArchive(dir); err == nil && s.FS == nil && !inArchive {
+		relTargets(dir, entries)
+	}
+	if err == nil && s.Annotate != nil {
+		start := time.Now()
+		s.annotate(dir, entries)
+		s.Timing.Add(PhaseAnnotate, start)
+	}
+	if err == nil && s.Pinned != nil {
+		s.pin(dir, entries)
+	}
+	return entries, hiddenCount, err
+}
+
+// relTargets rewrites absolute symlink targets that lie below the local
+// dir as paths relative to it, which is where the links resolve from.
+func relTargets(dir string, entries []Entry) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	for i, e := range entries {
+		if !e.IsSymlink || !filepath.IsAbs(e.Target) {
+			continue
+		}
+		rel, err := filepath.Rel(abs, e.Target)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			entries[i].Target = rel
+		}
+	}
+}
+
+// annotate adds s.Annotate's note to each entry's Note.
+func (s *Scanner) annotate(dir string, entries []Entry) {
+	join := path.Join
+	if s.FS == nil {
+		join = filepath.Join
+	}
+	s.parallel(len(entries), func(i int) {
+		note := s.Annotate(join(dir, entries[i].Name), entries[i])
+		if note != "" && entries[i].Note != "" {
+			note = entries[i].Note + "  " + note
+		}
+		if note != "" {
+			entries[i].Note = note
+		}
+	})
+}
+
+// pin marks the entries s.Pinned picks and moves them ahead of the
+// other dirs or files, keeping the sort among each.
+func (s *Scanner) pin(dir string, entries []Entry) {
+	join := path.Join
+	if s.FS == nil {
+		join = filepath.Join
+	}
+	dirs := 0
+	for i := range entries {
+		entries[i].Pinned = s.Pinned(join(dir, entries[i].Name), entries[i])
+		if entries[i].IsDir {
+			dirs++
+		}
+	}
+	first := func(a, b Entry) int {
+		switch {
+		case a.Pinned == b.Pinned:
+			return 0
+		case a.Pinned:
+			return -1
+		}
+		return 1
+	}
+	slices.SortStableFunc(entries[:dirs], first)
+	slices.SortStableFunc(entries[dirs:], first)
+}
+
+// parallel calls fn for 0..n-1 on a bounded pool of Workers goroutines.
+func (s *Scanner) parallel(n int, fn func(i int)) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, n)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// resolve maps dir to the filesystem and fs path that hold it.
+func (s *Scanner) resolve(dir string) (fs.FS, string, error) {
+	if s.FS != nil {
+		return s.FS, dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		// os adds the \\?\ prefix to long absolute paths, UNC ones
+		// included, but can't for relative ones
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	if archive, inner, ok := splitArchive(dir); ok {
+		fsys, err := OpenArchive(archive)
+		if err != nil {
+			return nil, "", err
+		}
+		if inner == "" {
+			inner = "."
+		}
+		return fsys, inner, nil
+	}
+	var fsys dirFS = os.DirFS(dir).(dirFS)
+	if s.Timeout > 0 {
+		fsys = timeoutFS{fsys, s.Timeout}
+	}
+	return osFS{fsys, dir}, ".", nil
+}
+
+type dirFS interface {
+	fs.ReadDirFS
+	fs.ReadFileFS
+	fs.StatFS
+	fs.ReadLinkFS
+}
+
+// osFS is os.DirFS(root), remembering root for the lookups that need
+// an OS path, such as Windows security info and reparse points.
+type osFS struct {
+	dirFS
+	root string
+}
+
+// osPath maps name in fsys to an OS path, if fsys is an osFS.
+func osPath(fsys fs.FS, name string) (string, bool) {
+	o, ok := fsys.(osFS)
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(o.root, filepath.FromSlash(name)), true
+}
+
+// scanFS lists dir in fsys; gi, when non-nil, holds the ignore rules
+// in force there.
+func (s *Scanner) scanFS(fsys fs.FS, dir string, gi *gitIgnore) ([]Entry, int, error) {
+	start := time.Now()
+	entries, err := fs.ReadDir(fsys, dir)
+	s.Timing.Add(PhaseReadDir, start)
+	s.Progress.add(len(entries))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var dirs, files []Entry
+	hiddenCount := 0
+	for _, e := range entries {
+		name := e.Name()
+		hidden := isHidden(e)
+
+		if hidden && !s.ShowAll {
+			hiddenCount++
+			continue
+		}
+		if s.Fast {
+			it, ok := s.fastEntry(dir, e, gi)
+			switch {
+			case !ok:
+			case it.IsDir:
+				if !s.FilesOnly {
+					dirs = append(dirs, it)
+				}
+			case s.matches(name):
+				files = append(files, it)
+			}
+			continue
+		}
+
+		start := time.Now()
+		info, err := e.Info()
+		s.Timing.Add(PhaseStat, start)
+		if errors.Is(err, ErrTimeout) {
+			// Show the name, at least
+			if it, ok := s.fastEntry(dir, e, gi); ok && (!it.IsDir || !s.FilesOnly) {
+				it.Unavailable, it.Flagged = true, true
+				if it.IsDir {
+					dirs = append(dirs, it)
+				} else {
+					files = append(files, it)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			s.Errors.add(err)
+			continue
+		}
+
+		full := path.Join(dir, name)
+		isDir := e.IsDir()
+		isSym := e.Type()&fs.ModeSymlink != 0
+
+		start = time.Now()
+		target := ""
+		if isSym {
+			target, _ = fs.ReadLink(fsys, full)
+			if ri, err := fs.Stat(fsys, full); err == nil {
+				isDir = ri.IsDir()
+			}
+		} else if t, ok := reparseLink(fsys, full, info); ok {
+			isSym, target = true, t
+		}
+		s.Timing.Add(PhaseLinks, start)
+		ignored := gi.ignored(full, isDir)
+		if ignored && s.GitIgnore || !s.inTimeRange(info.ModTime()) ||
+			s.Regex != nil && !s.Regex.MatchString(name) {
+			continue
+		}
+
+		ext := ""
+		if !isDir {
+			ext = strings.TrimPrefix(path.Ext(name), ".")
+		}
+
+		it := Entry{
+			Name:      name,
+			IsDir:     isDir,
+			IsSymlink: isSym,
+			Target:    target,
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+			Mode:      info.Mode(),
+			Hidden:    hidden,
+			Ignored:   ignored,
+			Pruned:    isDir && slices.Contains(s.Prune, name),
+			Ext:       ext,
+		}
+		if s.Owners {
+			it.Owner, it.Group = ownerOf(fsys, full, info)
+		}
+
+		if isDir && !s.FilesOnly {
+			dirs = append(dirs, it)
+		} else if !isDir && s.matches(name) && s.inSizeRange(it.Size) {
+			if s.Preview > 0 && (info.Mode().IsRegular() || isSym) {
+				it.Preview = previewLines(fsys, full, s.Preview)
+			}
+			files = append(files, it)
+		}
+	}
+
+	if !s.Fast {
+		start := time.Now()
+		s.countChildren(fsys, dir, dirs, gi)
+		s.Timing.Add(PhaseCounts, start)
+	}
+	if s.DiskUsage && !s.Fast {
+		start := time.Now()
+		s.sizeDirs(fsys, dir, dirs)
+		s.Timing.Add(PhaseSizes, start)
+	}
+	if s.Sniff || s.TextOnly || s.Dimensions || s.Media || s.Lines && !s.Fast {
+		s.readContents(fsys, dir, files)
+	}
+	if s.TextOnly {
+		files = slices.DeleteFunc(files, func(f Entry) bool { return !f.Sniffed || f.Binary })
+	}
+
+	sortDirs(dirs, s.Sort)
+	sortFiles(files, s.Sort)
+
+	return append(dirs, files...), hiddenCount, nil
+}
+
+// readContents fills in what the options ask to be read from inside
+// each file, on the worker pool.
+func (s *Scanner) readContents(fsys fs.FS, dir string, files []Entry) {
+	s.parallel(len(files), func(i int) {
+		f := &files[i]
+		if !f.Mode.IsRegular() && !f.IsSymlink {
+			return
+		}
+		full := path.Join(dir, f.Name)
+		if s.Sniff || s.TextOnly {
+			f.Binary, f.Detected, f.Sniffed = sniff(fsys, full)
+		}
+		if s.Dimensions {
+			f.Width, f.Height, _ = imageSize(fsys, full, strings.ToLower(f.Ext))
+		}
+		if s.Media {
+			ext := strings.ToLower(f.Ext)
+			f.Duration, f.Codec, _ = mediaInfo(fsys, full, ext)
+			f.Taken, _ = photoTaken(fsys, full, ext)
+			if ext == "pdf" {
+				f.Pages, _ = pdfPageCount(fsys, full)
+			}
+		}
+		if s.Lines && !s.Fast && !f.Binary {
+			f.Lines, f.Counted = countLines(fsys, full)
+		}
+	})
+}
+
+// matches reports whether a file name passes the Match globs.
+func (s *Scanner) matches(name string) bool {
+	if len(s.Match) == 0 {
+		return true
+	}
+	for _, pattern := range s.Match {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fastEntry is e as far as the dir listing tells, for Fast scans; false
+// when the ignore rules or Regex leave it out.
+func (s *Scanner) fastEntry(dir string, e fs.DirEntry, gi *gitIgnore) (Entry, bool) {
+	name := e.Name()
+	ignored := gi.ignored(path.Join(dir, name), e.IsDir())
+	if ignored && s.GitIgnore || s.Regex != nil && !s.Regex.MatchString(name) {
+		return Entry{}, false
+	}
+	it := Entry{
+		Name:      name,
+		IsDir:     e.IsDir(),
+		IsSymlink: e.Type()&fs.ModeSymlink != 0,
+		Mode:      e.Type(),
+		Hidden:    isHidden(e),
+		Ignored:   ignored,
+		Pruned:    e.IsDir() && slices.Contains(s.Prune, name),
+	}
+	if !it.IsDir {
+		it.Ext = strings.TrimPrefix(path.Ext(name), ".")
+	}
+	return it, true
+}
+
+// countChildren fills in SubDirs and SubFiles for each dir, reading
+// them on a bounded worker pool as each is its own ReadDir.
+func (s *Scanner) countChildren(fsys fs.FS, dir string, dirs []Entry, gi *gitIgnore) {
+	if !s.GitIgnore {
+		gi = nil // children that are only marked still count
+	}
+	s.parallel(len(dirs), func(i int) {
+		full := path.Join(dir, dirs[i].Name)
+		d := &dirs[i]
+		if d.Unavailable || d.Pruned {
+			return
+		}
+		sub := gi.load(fsys, full)
+		about := ""
+		err := readDirBatches(fsys, full, func(batch []fs.DirEntry) bool {
+			s.Progress.add(len(batch))
+			for _, se := range batch {
+				if n := se.Name(); (n == aboutFile || n == aboutFileAlt && about == "") && !se.IsDir() {
+					about = n
+				}
+				if !s.ShowAll && isHidden(se) {
+					continue
+				}
+				seDir := se.IsDir()
+				if s.Follow && se.Type()&fs.ModeSymlink != 0 {
+					if ri, err := fs.Stat(fsys, path.Join(full, se.Name())); err == nil {
+						seDir = ri.IsDir()
+					}
+				}
+				if sub.ignored(path.Join(full, se.Name()), seDir) {
+					continue
+				}
+				if seDir {
+					d.SubDirs++
+				} else {
+					d.SubFiles++
+				}
+				if s.MaxChildren > 0 && d.SubDirs+d.SubFiles >= s.MaxChildren {
+					d.SubCapped = true
+					return false
+				}
+			}
+			return true
+		})
+		if errors.Is(err, ErrTimeout) {
+			d.Unavailable, d.Flagged = true, true
+		} else {
+			s.Errors.add(err)
+		}
+		if about != "" && !d.Unavailable {
+			d.About = aboutLine(fsys, path.Join(full, about))
+		}
+	})
+}
+
+// A dir describes itself in the first line of one of these files, the
+// first one winning when it has both.
+const (
+	aboutFile    = ".peek"
+	aboutFileAlt = ".dirinfo"
+)
+
+// aboutLine is the first line of text in the file name.
+func aboutLine(fsys fs.FS, name string) string {
+	for _, l := range previewLines(fsys, name, 8) {
+		if l = strings.TrimSpace(l); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+// Entries are read this many at a time when counting children
+const countBatch = 1024
+
+// readDirBatches passes the entries of dir to fn a batch at a time, in
+// no particular order, until fn returns false, so that huge dirs need
+// not be read whole. Filesystems without fs.ReadDirFile are read at once.
+// The error is the one that ended the reading early, if any.
+func readDirBatches(fsys fs.FS, dir string, fn func([]fs.DirEntry) bool) error {
+	f, err := fsys.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rd, ok := f.(fs.ReadDirFile)
+	if !ok {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err == nil {
+			fn(entries)
+		}
+		return err
+	}
+	for {
+		batch, err := rd.ReadDir(countBatch)
+		if len(batch) > 0 && !fn(batch) || err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sizeDirs fills in DirSize for each dir using a bounded worker pool.
+// Symlinked dirs are skipped so a link can't pull in a foreign tree,
+// unless s.Follow is set.
+func (s *Scanner) sizeDirs(fsys fs.FS, dir string, dirs []Entry) {
+	s.parallel(len(dirs), func(i int) {
+		full := path.Join(dir, dirs[i].Name)
+		switch {
+		case dirs[i].Unavailable:
+		case s.Follow:
+			if info, err := fs.Stat(fsys, full); err == nil {
+				dirs[i].DirSize = s.followedSize(fsys, full, []fs.FileInfo{info})
+				dirs[i].DirSized = true
+			}
+		case !dirs[i].IsSymlink:
+			if _, local := osPath(fsys, full); local && s.SizeCache != nil {
+				dirs[i].DirSize = s.SizeCache.size(fsys, full, s.Progress, s.Errors)
+			} else {
+				dirs[i].DirSize = s.dirSize(fsys, full)
+			}
+			dirs[i].DirSized = true
+		}
+	})
+}
+
+// linkedDir reports whether d, found at name, is a dir reached through
+// a Windows junction or similar reparse point. fs.WalkDir descends into
+// those, unlike symlinks, so walks skip them.
+func linkedDir(fsys fs.FS, name string, d fs.DirEntry) bool {
+	if !d.IsDir() || d.Type()&fs.ModeIrregular == 0 {
+		return false
+	}
+	info, err := d.Info()
+	if err != nil {
+		return false
+	}
+	_, ok := reparseLink(fsys, name, info)
+	return ok
+}
+
+// Symlinked dirs nest at most this deep when followed, as a backstop
+// for filesystems that can't tell two dirs are the same one.
+const maxFollowDepth = 40
+
+// followedSize is dirSize through symlinks. ancestors holds the dirs
+// from the root down to dir; a link back to one of them is a cycle and
+// is skipped.
+func (s *Scanner) followedSize(fsys fs.FS, dir string, ancestors []fs.FileInfo) int64 {
+	if len(ancestors) > maxFollowDepth {
+		return 0
+	}
+	entries, err := fs.ReadDir(fsys, dir)
+	s.Progress.add(len(entries))
+	if err != nil {
+		s.Errors.add(err)
+		return 0
+	}
+	var total int64
+	for _, e := range entries {
+		full := path.Join(dir, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			s.Errors.add(err)
+			continue
+		}
+		if e.Type()&fs.ModeSymlink != 0 {
+			// A dangling link has nothing to add
+			if info, err = fs.Stat(fsys, full); err != nil {
+				continue
+			}
+		}
+		switch {
+		case info.Mode().IsRegular():
+			total += info.Size()
+		case info.IsDir() && !seen(info, ancestors):
+			total += s.followedSize(fsys, full, append(ancestors[:len(ancestors):len(ancestors)], info))
+		}
+	}
+	return total
+}
+
+func seen(info fs.FileInfo, ancestors []fs.FileInfo) bool {
+	for _, a := range ancestors {
+		if os.SameFile(a, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSize sums the sizes of all regular files below root.
+// Unreadable subtrees are skipped, and reported to s.Errors.
+func (s *Scanner) dirSize(fsys fs.FS, root string) int64 {
+	var total int64
+	fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			s.Errors.add(err)
+			return nil
+		}
+		s.Progress.add(1)
+		if p != root && linkedDir(fsys, p, d) {
+			return fs.SkipDir
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			} else {
+				s.Errors.add(err)
+			}
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,136 @@
+package peek
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testFS() fstest.MapFS {
+	file := func(size int) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(strings.Repeat("x", size))}
+	}
+	return fstest.MapFS{
+		"src/main.go":    file(40),
+		"src/util.go":    file(30),
+		"docs/guide.md":  file(10),
+		"zz.bin":         file(100),
+		"m.md":           file(20),
+		"a.txt":          file(5),
+		".env":           file(3),
+		".git/HEAD":      file(8),
+		"src/.hidden.go": file(1),
+	}
+}
+
+func names(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name)
+	}
+	return out
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Scanner
+		dir  string
+		want []string
+	}{
+		{"default", Scanner{}, ".", []string{"docs", "src", "zz.bin", "m.md", "a.txt"}},
+		{"show all", Scanner{ShowAll: true}, ".", []string{".git", "docs", "src", "zz.bin", "m.md", "a.txt", ".env"}},
+		{"files only", Scanner{FilesOnly: true}, ".", []string{"zz.bin", "m.md", "a.txt"}},
+		{"by name", Scanner{Sort: SortName}, ".", []string{"docs", "src", "a.txt", "m.md", "zz.bin"}},
+		{"match", Scanner{Match: []string{"*.md"}}, ".", []string{"docs", "src", "m.md"}},
+		{"min size", Scanner{MinSize: 10}, ".", []string{"docs", "src", "zz.bin", "m.md"}},
+		{"max size", Scanner{MaxSize: 20}, ".", []string{"docs", "src", "m.md", "a.txt"}},
+		{"regex", Scanner{Regex: regexp.MustCompile("^[dm]")}, ".", []string{"docs", "m.md"}},
+		{"subdir", Scanner{}, "src", []string{"main.go", "util.go"}},
+		{"fast", Scanner{Fast: true, Sort: SortName}, ".", []string{"docs", "src", "a.txt", "m.md", "zz.bin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.s
+			s.FS = testFS()
+			entries, err := s.Scan(tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(entries); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanDetails(t *testing.T) {
+	s := Scanner{FS: testFS(), DiskUsage: true}
+	entries, hidden, err := s.ScanHidden(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hidden != 2 {
+		t.Errorf("hidden = %d, want 2", hidden)
+	}
+	byName := map[string]Entry{}
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+	src := byName["src"]
+	if !src.IsDir || src.SubDirs != 0 || src.SubFiles != 2 {
+		t.Errorf("src: dir %v, %d dirs, %d files; want a dir with 2 files", src.IsDir, src.SubDirs, src.SubFiles)
+	}
+	if !src.DirSized || src.DirSize != 71 {
+		t.Errorf("src size = %d (sized %v), want 71", src.DirSize, src.DirSized)
+	}
+	if f := byName["m.md"]; f.IsDir || f.Size != 20 || f.Ext != "md" {
+		t.Errorf("m.md: dir %v, size %d, ext %q", f.IsDir, f.Size, f.Ext)
+	}
+}
+
+func TestScanMissingDir(t *testing.T) {
+	s := Scanner{FS: testFS()}
+	if _, err := s.Scan("nope"); err == nil {
+		t.Error("scanning a missing dir succeeded")
+	}
+}
+
+// Listing a dir by OS path and through os.DirFS as Scanner.FS go
+// through the same code and must agree.
+func TestScanOSMatchesFS(t *testing.T) {
+	dir := t.TempDir()
+	for name, f := range testFS() {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, f.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range []Scanner{{}, {ShowAll: true, DiskUsage: true}, {Sort: SortName, MinSize: 10}} {
+		byPath, err := s.Scan(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.FS = os.DirFS(dir)
+		byFS, err := s.Scan(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(byPath) != len(byFS) {
+			t.Fatalf("by path %q, through FS %q", names(byPath), names(byFS))
+		}
+		for i := range byPath {
+			a, b := byPath[i], byFS[i]
+			if a.Name != b.Name || a.Size != b.Size || a.DirSize != b.DirSize || a.SubFiles != b.SubFiles || a.Hidden != b.Hidden {
+				t.Errorf("by path %+v, through FS %+v", a, b)
+			}
+		}
+	}
+}
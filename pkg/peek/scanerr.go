@@ -0,0 +1,77 @@
+package peek
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ScanErrors collects what Scans couldn't read below the listed dir: an
+// entry that wouldn't stat, a subdir that couldn't be counted or sized.
+// Set Scanner.Errors to one to have those reported rather than skipped.
+type ScanErrors struct {
+	mu   sync.Mutex
+	errs []error
+	seen map[string]bool
+}
+
+// add records err once. A nil ScanErrors ignores it.
+func (c *ScanErrors) add(err error) {
+	if c == nil || err == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen == nil {
+		c.seen = map[string]bool{}
+	}
+	if msg := err.Error(); !c.seen[msg] {
+		c.seen[msg] = true
+		c.errs = append(c.errs, err)
+	}
+}
+
+// List is the errors collected, ordered by path.
+func (c *ScanErrors) List() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	errs := slices.Clone(c.errs)
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(errorLine(a), errorLine(b)) })
+	return errs
+}
+
+// errorLine puts the reason first, as in "permission denied on a/b".
+func errorLine(err error) string {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return fmt.Sprintf("%s on %s", pe.Err, pe.Path)
+	}
+	return err.Error()
+}
+
+// Errors shown below the panels before the rest are summed up
+const maxErrorLines = 8
+
+// Errors draws errs as a short list for below the panels, or "" when
+// there are none.
+func (r PanelRenderer) Errors(errs []error) string {
+	if len(errs) == 0 {
+		return ""
+	}
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	var lines []string
+	for i, err := range errs {
+		if i == maxErrorLines {
+			lines = append(lines, CountStyle.Render(fmt.Sprintf("  … and %d more", len(errs)-i)))
+			break
+		}
+		lines = append(lines, ErrStyle.Render("  ✗ "+Truncate(errorLine(err), width-6)))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
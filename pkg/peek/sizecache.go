@@ -0,0 +1,132 @@
+package peek
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// SizeCache keeps what recursive dir sizes are made of between runs:
+// per dir, keyed by its absolute OS path and mtime, the total of its
+// own files and the names of its subdirs. A dir whose mtime is
+// unchanged is not listed again, so re-sizing a large tree costs a stat
+// per dir. Files that grow or shrink in place don't change their dir's
+// mtime; such changes show once the dir itself changes.
+type SizeCache struct {
+	file  string
+	mu    sync.Mutex
+	dirs  map[string]cachedDir
+	dirty bool
+}
+
+type cachedDir struct {
+	MTime   int64    `json:"mtime"` // UnixNano
+	Files   int64    `json:"files"` // bytes in regular files directly inside
+	Subdirs []string `json:"subdirs,omitempty"`
+}
+
+// OpenSizeCache loads the cache kept in file. A missing or unreadable
+// file gives an empty cache, written out by Save.
+func OpenSizeCache(file string) *SizeCache {
+	c := &SizeCache{file: file, dirs: map[string]cachedDir{}}
+	if data, err := os.ReadFile(file); err == nil {
+		if json.Unmarshal(data, &c.dirs) != nil {
+			c.dirs = map[string]cachedDir{}
+		}
+	}
+	return c
+}
+
+// Save writes the cache back to its file, if anything changed.
+func (c *SizeCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.Marshal(c.dirs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.file), 0o755); err != nil {
+		return err
+	}
+	tmp := c.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.file); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// size is dirSize for a dir of the OS filesystem, reusing what the cache
+// knows of each dir below it. What can't be read goes to errs.
+func (c *SizeCache) size(fsys fs.FS, name string, p *Progress, errs *ScanErrors) int64 {
+	key, ok := osPath(fsys, name)
+	info, err := fs.Stat(fsys, name)
+	if !ok || err != nil {
+		return 0
+	}
+	// The path is as the user typed it; the same relative path names
+	// a different dir from elsewhere
+	if key, err = filepath.Abs(key); err != nil {
+		return 0
+	}
+	mtime := info.ModTime().UnixNano()
+
+	c.mu.Lock()
+	rec, hit := c.dirs[key]
+	c.mu.Unlock()
+	if !hit || rec.MTime != mtime {
+		if rec, err = listDir(fsys, name, p, errs); errors.Is(err, fs.ErrNotExist) {
+			return 0
+		}
+		if err != nil {
+			// Not cached, so it's reported again next time
+			errs.add(err)
+		} else {
+			rec.MTime = mtime
+			c.mu.Lock()
+			c.dirs[key], c.dirty = rec, true
+			c.mu.Unlock()
+		}
+	}
+
+	total := rec.Files
+	for _, sub := range rec.Subdirs {
+		total += c.size(fsys, path.Join(name, sub), p, errs)
+	}
+	return total
+}
+
+// listDir totals the files directly in name and names its subdirs, as
+// dirSize would walk them.
+func listDir(fsys fs.FS, name string, p *Progress, errs *ScanErrors) (cachedDir, error) {
+	var rec cachedDir
+	entries, err := fs.ReadDir(fsys, name)
+	p.add(len(entries))
+	for _, d := range entries {
+		full := path.Join(name, d.Name())
+		switch {
+		case d.IsDir():
+			if !linkedDir(fsys, full, d) {
+				rec.Subdirs = append(rec.Subdirs, d.Name())
+			}
+		case d.Type().IsRegular():
+			if info, err := d.Info(); err == nil {
+				rec.Files += info.Size()
+			} else {
+				errs.add(err)
+			}
+		}
+	}
+	return rec, err
+}
@@ -0,0 +1,40 @@
+package peek
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Two trees laid out alike, listed by the same relative path from
+// different dirs, must not share cached sizes.
+func TestSizeCacheRelativePaths(t *testing.T) {
+	root := t.TempDir()
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for tree, size := range map[string]int{"a": 10, "b": 500} {
+		src := filepath.Join(root, tree, "proj", "src")
+		if err := os.MkdirAll(src, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, "f"), []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(src, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := OpenSizeCache(filepath.Join(t.TempDir(), "sizes.json"))
+	for tree, want := range map[string]int64{"a": 10, "b": 500} {
+		t.Chdir(filepath.Join(root, tree))
+		entries, err := (&Scanner{DiskUsage: true, SizeCache: cache}).Scan("proj")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].DirSize != want {
+			t.Errorf("%s: got %+v, want src sized %d", tree, entries, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package peek
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Snapshot is a saved listing to compare later scans against.
+type Snapshot struct {
+	Path    string          `json:"path"`
+	Taken   time.Time       `json:"taken"`
+	Entries []snapshotEntry `json:"entries"`
+}
+
+type snapshotEntry struct {
+	Name    string    `json:"name"`
+	Dir     bool      `json:"dir,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// NewSnapshot records entries, as scanned from path, at the current time.
+func NewSnapshot(path string, entries []Entry) Snapshot {
+	snap := Snapshot{Path: path, Taken: time.Now()}
+	for _, e := range entries {
+		snap.Entries = append(snap.Entries, snapshotEntry{e.Name, e.IsDir, e.Size, e.ModTime})
+	}
+	return snap
+}
+
+func (s Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ReadSnapshot decodes a snapshot written by Snapshot.Write.
+func ReadSnapshot(r io.Reader) (Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("reading snapshot: %w", err)
+	}
+	return s, nil
+}
+
+// Compare diffs the snapshot, as side A, against a fresh listing: OnlyA
+// holds removed entries, OnlyB added ones, and Changed files that were
+// resized or changed type. Dir sizes are not compared.
+func (s Snapshot) Compare(entries []Entry) Diff {
+	old := map[string]Entry{}
+	for _, se := range s.Entries {
+		old[se.Name] = Entry{Name: se.Name, IsDir: se.Dir, Size: se.Size, ModTime: se.ModTime}
+	}
+	var d Diff
+	seen := map[string]bool{}
+	for _, e := range entries {
+		seen[e.Name] = true
+		prev, ok := old[e.Name]
+		switch {
+		case !ok:
+			d.OnlyB = append(d.OnlyB, e)
+		case prev.IsDir != e.IsDir || !e.IsDir && prev.Size != e.Size:
+			d.Changed = append(d.Changed, Change{prev, e})
+		}
+	}
+	for _, se := range s.Entries {
+		if !seen[se.Name] {
+			d.OnlyA = append(d.OnlyA, old[se.Name])
+		}
+	}
+	sort.Slice(d.OnlyA, func(i, j int) bool { return d.OnlyA[i].Name < d.OnlyA[j].Name })
+	sort.Slice(d.OnlyB, func(i, j int) bool { return d.OnlyB[i].Name < d.OnlyB[j].Name })
+	return d
+}
+
+// SnapshotDiff draws the result of Snapshot.Compare as REMOVED, ADDED
+// and RESIZED boxes, with a footer dating the snapshot.
+func (r PanelRenderer) SnapshotDiff(d Diff, taken time.Time) string {
+	since := "since " + taken.Local().Format("Jan _2 15:04")
+	footer := "unchanged " + since
+	if len(d.OnlyA)+len(d.OnlyB)+len(d.Changed) > 0 {
+		footer = fmt.Sprintf("%d removed  ·  %d added  ·  %d resized  %s", len(d.OnlyA), len(d.OnlyB), len(d.Changed), since)
+	}
+	return r.diffView(d, [3]string{"REMOVED", "ADDED", "RESIZED"}, footer)
+}
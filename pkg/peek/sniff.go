@@ -0,0 +1,82 @@
+package peek
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// A magic number: the bytes a kind of file starts with, at offset.
+// Short ones that text could begin with also need a NUL byte.
+type magic struct {
+	offset   int
+	sig      string
+	kind     string
+	needsNUL bool
+}
+
+// Checked in order; text kinds come last in sniff
+var magics = []magic{
+	{0, "\x89PNG\r\n\x1a\n", "PNG image", false},
+	{0, "\xff\xd8\xff", "JPEG image", false},
+	{0, "GIF87a", "GIF image", false},
+	{0, "GIF89a", "GIF image", false},
+	{8, "WEBP", "WebP image", false},
+	{0, "%PDF-", "PDF document", false},
+	{0, "\x7fELF", "ELF binary", false},
+	{0, "\xcf\xfa\xed\xfe", "Mach-O binary", false},
+	{0, "\xce\xfa\xed\xfe", "Mach-O binary", false},
+	{0, "\xca\xfe\xba\xbe", "Mach-O binary", false},
+	{0, "MZ", "PE executable", true},
+	{0, "\x00asm", "WebAssembly", false},
+	{0, "\x1f\x8b", "gzip", false},
+	{0, "PK\x03\x04", "zip", false},
+	{0, "BZh", "bzip2", true},
+	{0, "\xfd7zXZ\x00", "xz", false},
+	{0, "\x28\xb5\x2f\xfd", "zstd", false},
+	{0, "7z\xbc\xaf\x27\x1c", "7z", false},
+	{257, "ustar", "tar", false},
+	{0, "SQLite format 3\x00", "SQLite database", false},
+	{0, "OggS", "Ogg media", false},
+	{0, "fLaC", "FLAC audio", false},
+	{0, "ID3", "MP3 audio", true},
+	{4, "ftyp", "MP4 media", false},
+	{0, "\x1a\x45\xdf\xa3", "Matroska media", false},
+}
+
+// sniff reads the head of the file name and reports whether it is
+// binary, a NUL byte in its first previewBytes, and the kind its magic
+// number or byte order mark names, if any. ok is false when the file
+// can't be read.
+func sniff(fsys fs.FS, name string) (binary bool, kind string, ok bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false, "", false
+	}
+	defer f.Close()
+	buf := make([]byte, previewBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, "", false
+	}
+	binary, kind = sniffBytes(buf[:n])
+	return binary, kind, true
+}
+
+// sniffBytes judges the start of a file, as sniff does.
+func sniffBytes(buf []byte) (binary bool, kind string) {
+	nul := bytes.IndexByte(buf, 0) >= 0
+	for _, m := range magics {
+		if len(buf) >= m.offset+len(m.sig) && string(buf[m.offset:m.offset+len(m.sig)]) == m.sig && (nul || !m.needsNUL) {
+			return true, m.kind
+		}
+	}
+	switch {
+	case bytes.HasPrefix(buf, []byte("\xff\xfe")) || bytes.HasPrefix(buf, []byte("\xfe\xff")):
+		// Full of NULs, but text all the same
+		return false, "UTF-16 text"
+	case bytes.HasPrefix(buf, []byte("\xef\xbb\xbf")):
+		return false, "UTF-8 text with BOM"
+	}
+	return nul, ""
+}
@@ -0,0 +1,110 @@
+package peek
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKey orders both panels of a listing.
+type SortKey int
+
+const (
+	SortDefault SortKey = iota // dirs by name, files by decreasing size
+	SortName
+	SortSize  // largest first; dirs use DirSize when known, else child count
+	SortMtime // newest first
+	SortExt
+	SortNone  // directory order as returned by the OS
+	SortTaken // photos by EXIF capture time, oldest first; needs Scanner.Media
+)
+
+var sortNames = map[string]SortKey{
+	"name":  SortName,
+	"size":  SortSize,
+	"mtime": SortMtime,
+	"ext":   SortExt,
+	"none":  SortNone,
+	"taken": SortTaken,
+}
+
+// ParseSortKey maps a --sort value to its key.
+func ParseSortKey(s string) (SortKey, error) {
+	if k, ok := sortNames[strings.ToLower(s)]; ok {
+		return k, nil
+	}
+	return SortDefault, fmt.Errorf("unknown sort key %q (name, size, mtime, ext, taken, none)", s)
+}
+
+func sortDirs(dirs []Entry, key SortKey) {
+	if key == SortDefault {
+		key = SortName
+	}
+	sortBy(dirs, key)
+}
+
+func sortFiles(files []Entry, key SortKey) {
+	if key == SortDefault {
+		key = SortSize
+	}
+	sortBy(files, key)
+}
+
+func sortBy(items []Entry, key SortKey) {
+	if key == SortNone {
+		return
+	}
+	byName := func(i, j int) bool {
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	}
+	less := byName
+	switch key {
+	case SortSize:
+		less = func(i, j int) bool {
+			si, sj := weight(items[i]), weight(items[j])
+			if si != sj {
+				return si > sj
+			}
+			return byName(i, j)
+		}
+	case SortMtime:
+		less = func(i, j int) bool {
+			if !items[i].ModTime.Equal(items[j].ModTime) {
+				return items[i].ModTime.After(items[j].ModTime)
+			}
+			return byName(i, j)
+		}
+	case SortTaken:
+		// Files without a date follow, by name
+		less = func(i, j int) bool {
+			ti, tj := items[i].Taken, items[j].Taken
+			if ti.IsZero() != tj.IsZero() {
+				return tj.IsZero()
+			}
+			if !ti.Equal(tj) {
+				return ti.Before(tj)
+			}
+			return byName(i, j)
+		}
+	case SortExt:
+		less = func(i, j int) bool {
+			ei, ej := strings.ToLower(items[i].Ext), strings.ToLower(items[j].Ext)
+			if ei != ej {
+				return ei < ej
+			}
+			return byName(i, j)
+		}
+	}
+	sort.SliceStable(items, less)
+}
+
+// weight is what SortSize compares.
+func weight(e Entry) int64 {
+	if !e.IsDir {
+		return e.Size
+	}
+	if e.DirSized {
+		return e.DirSize
+	}
+	return int64(e.SubDirs + e.SubFiles)
+}
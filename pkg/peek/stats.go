@@ -0,0 +1,134 @@
+package peek
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ExtStat totals the files below a dir sharing one extension.
+type ExtStat struct {
+	Ext   string // lower-cased, without the dot; empty for none
+	Count int
+	Size  int64
+}
+
+// Stats walks dir recursively and totals its files by extension,
+// largest total first. ShowAll and Match apply as in Scan.
+func (s *Scanner) Stats(dir string) ([]ExtStat, error) {
+	fsys, root, err := s.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	byExt := map[string]*ExtStat{}
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		if p != root && !s.ShowAll && isHidden(d) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if p != root && linkedDir(fsys, p, d) {
+			return fs.SkipDir
+		}
+		if !d.Type().IsRegular() || !s.matches(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		ext := strings.ToLower(strings.TrimPrefix(path.Ext(d.Name()), "."))
+		st := byExt[ext]
+		if st == nil {
+			st = &ExtStat{Ext: ext}
+			byExt[ext] = st
+		}
+		st.Count++
+		st.Size += info.Size()
+		return nil
+	})
+	if err != nil {
+		if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+			pe.Path = dir
+		}
+		return nil, err
+	}
+
+	stats := make([]ExtStat, 0, len(byExt))
+	for _, st := range byExt {
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Size != stats[j].Size {
+			return stats[i].Size > stats[j].Size
+		}
+		return stats[i].Ext < stats[j].Ext
+	})
+	return stats, nil
+}
+
+// StatsRenderer draws extension totals as a table inside a box.
+type StatsRenderer struct {
+	Width int    // terminal columns; 80 when zero
+	Title string // shown above the table, usually the root path
+}
+
+func (r StatsRenderer) Render(w io.Writer, stats []ExtStat) error {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	inner := max(width-2, 20)
+	lineWidth := min(inner-4, maxNameLen)
+
+	var total int64
+	var files int
+	for _, st := range stats {
+		total += st.Size
+		files += st.Count
+	}
+
+	// Columns: ext and a dot leader up to the count, then size and share
+	const tailW = 16
+	head := "EXT" + strings.Repeat(" ", max(lineWidth-tailW-8, 1)) + "FILES" + fmt.Sprintf("%9s%7s", "SIZE", "%")
+	lines := []string{TitleStyle.Render(head)}
+	for _, st := range stats {
+		ext := "." + st.Ext
+		if st.Ext == "" {
+			ext = "(none)"
+		}
+		ext = Truncate(ext, lineWidth-tailW-12)
+		pct := 0.0
+		if total > 0 {
+			pct = float64(st.Size) * 100 / float64(total)
+		}
+		nums := fmt.Sprintf("%d%9s%6.1f%%", st.Count, HumanSize(st.Size), pct)
+		dots := max(lineWidth-textWidth(ext)-len(nums), 3)
+		lines = append(lines, fileNameStyle.Render(ext)+" "+dotLeaderStyle.Render(strings.Repeat("·", dots-2))+" "+metaStyle.Render(nums))
+	}
+	if len(stats) == 0 {
+		lines = append(lines, CountStyle.Render("no files"))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(inner)
+	body := makeHeader(Truncate(r.Title, lineWidth), lineWidth) + strings.Join(lines, "\n")
+	summary := fmt.Sprintf("%s  ·  %s  ·  %s", Plural(files, "file"), HumanSize(total), Plural(len(stats), "extension"))
+	_, err := fmt.Fprintf(w, "\n%s\n\n  %s\n\n", box.Render(body), CountStyle.Render(summary))
+	return err
+}
@@ -0,0 +1,111 @@
+package peek
+
+import "github.com/charmbracelet/lipgloss"
+
+// Box border; blank in plain mode
+var boxBorder = lipgloss.RoundedBorder()
+
+var (
+	borderColor lipgloss.Color
+
+	// Title inside box
+	TitleStyle lipgloss.Style
+
+	// Separator line
+	sepStyle lipgloss.Style
+
+	// Dir indicator
+	dirIndicator lipgloss.Style
+
+	// Dir names
+	dirNameStyle lipgloss.Style
+	dotDirStyle  lipgloss.Style
+
+	// File names
+	fileNameStyle lipgloss.Style
+	dotFileStyle  lipgloss.Style
+
+	// Metadata (size, child counts)
+	metaStyle lipgloss.Style
+
+	// Dot leader
+	dotLeaderStyle lipgloss.Style
+
+	// Symlinks
+	symNameStyle lipgloss.Style
+
+	// Executable files
+	execNameStyle lipgloss.Style
+
+	// Binary files, once sniffed
+	binNameStyle lipgloss.Style
+
+	// File preview lines
+	previewStyle lipgloss.Style
+
+	// Footer
+	CountStyle lipgloss.Style
+
+	// Selected entry in interactive mode
+	cursorStyle lipgloss.Style
+
+	// Error
+	ErrStyle lipgloss.Style
+
+	// Setuid, setgid and sticky bits in long-mode permissions
+	specialStyle lipgloss.Style
+)
+
+func init() {
+	// No style here adapts to the background, so lipgloss never needs
+	// to ask the terminal for it. Saying so up front keeps bubbletea's
+	// init, which runs after this package's as it sorts later, from
+	// sending the query on every run and stalling for seconds on
+	// terminals that don't answer.
+	lipgloss.SetHasDarkBackground(true)
+	SetTheme(Themes[DefaultTheme])
+}
+
+// SetTheme restyles all subsequent rendering with t.
+func SetTheme(t Theme) {
+	c := func(s string) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(s))
+	}
+	boxBorder = lipgloss.RoundedBorder()
+	borderColor = lipgloss.Color(t.Border)
+	TitleStyle = c(t.Title).Bold(true)
+	sepStyle = c(t.Separator)
+	dirIndicator = c(t.Subtitle)
+	dirNameStyle = c(t.Dir).Bold(true)
+	dotDirStyle = c(t.DotDir)
+	fileNameStyle = c(t.File)
+	dotFileStyle = c(t.DotFile)
+	metaStyle = c(t.Subtitle)
+	dotLeaderStyle = c(t.Leader)
+	symNameStyle = c(t.Symlink).Italic(true)
+	execNameStyle = c(t.Exec).Bold(true)
+	binNameStyle = c(t.Muted).Italic(true)
+	previewStyle = c(t.Muted).Faint(true)
+	CountStyle = c(t.Muted)
+	cursorStyle = c(t.Title).Reverse(true).Bold(true)
+	ErrStyle = c(t.Error)
+	specialStyle = c(t.Error).Bold(true)
+	syntaxStyle, drawImages = t.Syntax, true
+	if syntaxStyle == "" {
+		syntaxStyle = "monokai"
+	}
+}
+
+// SetPlain drops all styling and blanks the box borders, so output
+// carries no escape codes or line-drawing; for pipes and NO_COLOR.
+func SetPlain() {
+	none := lipgloss.NewStyle()
+	boxBorder = lipgloss.HiddenBorder()
+	borderColor = ""
+	TitleStyle, sepStyle, dirIndicator = none, none, none
+	dirNameStyle, dotDirStyle, fileNameStyle, dotFileStyle = none, none, none, none
+	metaStyle, dotLeaderStyle, symNameStyle, execNameStyle, previewStyle = none, none, none, none, none
+	binNameStyle = none
+	CountStyle, cursorStyle, ErrStyle, specialStyle = none, none.Reverse(true), none, none
+	syntaxStyle, drawImages = "", false
+}
@@ -0,0 +1,37 @@
+package peek
+
+import (
+	"bufio"
+	"io"
+	"text/template"
+)
+
+// TemplateFuncs are available to templates parsed with NewTemplateRenderer.
+var TemplateFuncs = template.FuncMap{
+	"human": HumanSize,
+	"kind":  func(e Entry) string { return groupOf(KindOf(e)) },
+}
+
+// TemplateRenderer executes a text/template once per entry, with the
+// Entry as its data, and ends each with a newline. No styling is applied.
+type TemplateRenderer struct {
+	Tmpl *template.Template
+}
+
+// NewTemplateRenderer parses text, e.g. "{{.Name}}\t{{human .Size}}",
+// with TemplateFuncs.
+func NewTemplateRenderer(text string) (TemplateRenderer, error) {
+	t, err := template.New("format").Funcs(TemplateFuncs).Parse(text)
+	return TemplateRenderer{Tmpl: t}, err
+}
+
+func (r TemplateRenderer) Render(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		if err := r.Tmpl.Execute(bw, e); err != nil {
+			return err
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
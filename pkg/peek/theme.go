@@ -0,0 +1,121 @@
+package peek
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Theme maps the semantic roles of a listing to lipgloss colors
+// ("#00ff66", ANSI "10", ...).
+type Theme struct {
+	Title     string // panel titles
+	Dir       string
+	DotDir    string // hidden dirs
+	File      string
+	DotFile   string // hidden files
+	Symlink   string
+	Exec      string // executable files
+	Subtitle  string // sizes, child counts, dir indicator
+	Separator string // rule under panel titles
+	Leader    string // dot leaders
+	Border    string
+	Muted     string // footer and hints
+	Error     string
+	Syntax    string // chroma style for peek cat, e.g. "monokai"
+}
+
+// Themes holds the built-in palettes by name.
+var Themes = map[string]Theme{
+	"green": {
+		Title: "#00ff66", Dir: "#00ff66", DotDir: "#006633",
+		File: "#00dd55", DotFile: "#005c2e", Symlink: "#00ffaa", Exec: "#ccff33",
+		Subtitle: "#008844", Separator: "#003d1a", Leader: "#002a11",
+		Border: "#004d26", Muted: "#006633", Error: "#ff3334", Syntax: "monokai",
+	},
+	"amber": {
+		Title: "#ffb000", Dir: "#ffb000", DotDir: "#805800",
+		File: "#e09a00", DotFile: "#6b4a00", Symlink: "#ffd060", Exec: "#ff7a1a",
+		Subtitle: "#a06e00", Separator: "#4d3500", Leader: "#332300",
+		Border: "#5c3f00", Muted: "#805800", Error: "#ff3334", Syntax: "gruvbox",
+	},
+	"ocean": {
+		Title: "#5fd7ff", Dir: "#5fd7ff", DotDir: "#2a6f8a",
+		File: "#4fb8e0", DotFile: "#25607a", Symlink: "#a0e8ff", Exec: "#7fffc4",
+		Subtitle: "#3a8fb0", Separator: "#123a4a", Leader: "#0c2833",
+		Border: "#1a5066", Muted: "#2a6f8a", Error: "#ff5f5f", Syntax: "nord",
+	},
+	// For light terminal backgrounds
+	"light": {
+		Title: "#006b2e", Dir: "#006b2e", DotDir: "#6a9a7a",
+		File: "#1a5c33", DotFile: "#7fa58c", Symlink: "#00806b", Exec: "#8a5a00",
+		Subtitle: "#4a7a5a", Separator: "#b5d4bf", Leader: "#c8e0d0",
+		Border: "#8fbf9f", Muted: "#6a9a7a", Error: "#c00000", Syntax: "github",
+	},
+	"mono": {
+		Title: "15", Dir: "15", DotDir: "245",
+		File: "252", DotFile: "243", Symlink: "250", Exec: "15",
+		Subtitle: "245", Separator: "238", Leader: "236",
+		Border: "240", Muted: "243", Error: "9", Syntax: "bw",
+	},
+}
+
+// DefaultTheme is the name of the palette used when none is chosen.
+const DefaultTheme = "green"
+
+// ThemeNames lists the built-in themes alphabetically.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Override returns a copy of t with the given roles replaced.
+// Role names are the lower-cased field names: "dir", "dotfile", ...
+func (t Theme) Override(roles map[string]string) (Theme, error) {
+	for role, color := range roles {
+		field := t.role(strings.ToLower(role))
+		if field == nil {
+			return t, fmt.Errorf("unknown theme role %q", role)
+		}
+		*field = color
+	}
+	return t, nil
+}
+
+func (t *Theme) role(name string) *string {
+	switch name {
+	case "title":
+		return &t.Title
+	case "dir":
+		return &t.Dir
+	case "dotdir":
+		return &t.DotDir
+	case "file":
+		return &t.File
+	case "dotfile":
+		return &t.DotFile
+	case "symlink":
+		return &t.Symlink
+	case "exec":
+		return &t.Exec
+	case "subtitle":
+		return &t.Subtitle
+	case "separator":
+		return &t.Separator
+	case "leader":
+		return &t.Leader
+	case "border":
+		return &t.Border
+	case "muted":
+		return &t.Muted
+	case "error":
+		return &t.Error
+	case "syntax":
+		return &t.Syntax
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package peek
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Ways of drawing an image in the terminal, for CatRenderer.Graphics.
+const (
+	GraphicsKitty  = "kitty"  // the kitty graphics protocol (kitty, WezTerm, Ghostty)
+	GraphicsSixel  = "sixel"  // DEC sixels (foot, mlterm, xterm -ti vt340)
+	GraphicsBlocks = "blocks" // half-block characters in 24-bit color, anywhere
+)
+
+// Cells are about twice as tall as they are wide.
+const cellAspect = 2
+
+// drawImages is off in plain mode, where blocks have no colors.
+var drawImages = true
+
+// thumbSize fits a w×h image in cols×rows cells, keeping its shape.
+func thumbSize(w, h, cols, rows int) (int, int) {
+	if w <= 0 || h <= 0 {
+		return 0, 0
+	}
+	c := cols
+	r := h * c / (w * cellAspect)
+	if r > rows {
+		r = rows
+		c = w * r * cellAspect / h
+	}
+	return max(c, 1), max(r, 1)
+}
+
+// Mosaic draws img in at most cols×rows cells of "▀", each colored with
+// the pixel above as foreground and the one below as background.
+// Transparent pixels keep the terminal's background.
+func Mosaic(img image.Image, cols, rows int) []string {
+	if !drawImages {
+		return nil
+	}
+	b := img.Bounds()
+	c, r := thumbSize(b.Dx(), b.Dy(), cols, rows)
+	if c == 0 {
+		return nil
+	}
+	px := scaled(img, c, r*2)
+	lines := make([]string, r)
+	for y := range r {
+		var line strings.Builder
+		for x := range c {
+			top, bottom := px[y*2*c+x], px[(y*2+1)*c+x]
+			var cell lipgloss.Style
+			glyph := "▀"
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				line.WriteByte(' ')
+				continue
+			case top.A < 128:
+				cell, glyph = lipgloss.NewStyle().Foreground(hexColor(bottom)), "▄"
+			case bottom.A < 128:
+				cell = lipgloss.NewStyle().Foreground(hexColor(top))
+			default:
+				cell = lipgloss.NewStyle().Foreground(hexColor(top)).Background(hexColor(bottom))
+			}
+			line.WriteString(cell.Render(glyph))
+		}
+		lines[y] = line.String()
+	}
+	return lines
+}
+
+// scaled averages img down (or samples it up) to w×h pixels, row by row.
+func scaled(img image.Image, w, h int) []color.NRGBA {
+	b := img.Bounds()
+	out := make([]color.NRGBA, w*h)
+	for y := range h {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/h, y0+1)
+		for x := range w {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(b.Min.X+(x+1)*b.Dx()/w, x0+1)
+			// Every pixel of small boxes, a 4×4 grid of big ones
+			sx, sy := max((x1-x0)/4, 1), max((y1-y0)/4, 1)
+			var r, g, bl, a, n uint32
+			for yy := y0; yy < y1; yy += sy {
+				for xx := x0; xx < x1; xx += sx {
+					c := color.NRGBAModel.Convert(img.At(xx, yy)).(color.NRGBA)
+					r, g, bl, a = r+uint32(c.R), g+uint32(c.G), bl+uint32(c.B), a+uint32(c.A)
+					n++
+				}
+			}
+			out[y*w+x] = color.NRGBA{uint8(r / n), uint8(g / n), uint8(bl / n), uint8(a / n)}
+		}
+	}
+	return out
+}
+
+func hexColor(c color.NRGBA) lipgloss.Color {
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+}
+
+// thumbnail is img scaled to w×h pixels.
+func thumbnail(img image.Image, w, h int) *image.NRGBA {
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i, p := range scaled(img, w, h) {
+		out.SetNRGBA(i%w, i/w, p)
+	}
+	return out
+}
+
+// KittyImage is the escape sequence that has a kitty-protocol terminal
+// draw img in at most cols×rows cells at the cursor, as PNG in base64
+// chunks.
+func KittyImage(img image.Image, cols, rows int) string {
+	b := img.Bounds()
+	c, r := thumbSize(b.Dx(), b.Dy(), cols, rows)
+	if c == 0 {
+		return ""
+	}
+	if b.Dx() > c*cellW || b.Dy() > r*cellH {
+		img = thumbnail(img, c*cellW, r*cellH)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	const chunk = 4096
+	var out strings.Builder
+	for i := 0; i < len(data); i += chunk {
+		more := 0
+		if i+chunk < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&out, "\x1b_Gf=100,a=T,q=2,c=%d,r=%d,m=%d;", c, r, more)
+		} else {
+			fmt.Fprintf(&out, "\x1b_Gm=%d;", more)
+		}
+		out.WriteString(data[i:min(i+chunk, len(data))])
+		out.WriteString("\x1b\\")
+	}
+	return out.String()
+}
+
+// Pixels a cell is taken to hold when sizing sixels, which are drawn
+// pixel for pixel, and kitty thumbnails.
+const cellW, cellH = 10, 20
+
+// SixelImage encodes img as sixels, scaled to about cols×rows cells,
+// in a 6×6×6 color cube. Pixels under half opaque are left out.
+func SixelImage(img image.Image, cols, rows int) string {
+	b := img.Bounds()
+	c, r := thumbSize(b.Dx(), b.Dy(), cols, rows)
+	if c == 0 {
+		return ""
+	}
+	w, h := c*cellW, r*cellH
+	px := scaled(img, w, h)
+	idx := make([]int, len(px)) // palette index per pixel, -1 for none
+	for i, p := range px {
+		idx[i] = -1
+		if p.A >= 128 {
+			idx[i] = int(p.R)*6/256*36 + int(p.G)*6/256*6 + int(p.B)*6/256
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for i := range 216 {
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+	for band := 0; band < h; band += 6 {
+		// Each color present in the band is one pass over it
+		used := map[int]bool{}
+		for y := band; y < min(band+6, h); y++ {
+			for x := range w {
+				if i := idx[y*w+x]; i >= 0 {
+					used[i] = true
+				}
+			}
+		}
+		first := true
+		for col := range 216 {
+			if !used[col] {
+				continue
+			}
+			if !first {
+				out.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&out, "#%d", col)
+			run, last := 0, byte(0)
+			flush := func() {
+				switch {
+				case run > 3:
+					fmt.Fprintf(&out, "!%d%c", run, last)
+				case run > 0:
+					out.WriteString(strings.Repeat(string(last), run))
+				}
+			}
+			for x := range w {
+				var bits byte
+				for dy := range 6 {
+					if y := band + dy; y < h && idx[y*w+x] == col {
+						bits |= 1 << dy
+					}
+				}
+				ch := 63 + bits
+				if ch != last {
+					flush()
+					run, last = 0, ch
+				}
+				run++
+			}
+			flush()
+		}
+		out.WriteByte('-')
+	}
+	out.WriteString("\x1b\\")
+	return out.String()
+}
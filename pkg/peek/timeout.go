@@ -0,0 +1,127 @@
+package peek
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// ErrTimeout is the error of a filesystem call abandoned after
+// Scanner.Timeout.
+var ErrTimeout = errors.New("timed out")
+
+// timeoutFS gives up on calls into an OS filesystem that take longer
+// than d, as those on a dead network mount can block forever. The call
+// itself can't be cancelled: its goroutine is left behind until the
+// kernel returns.
+type timeoutFS struct {
+	fsys dirFS
+	d    time.Duration
+}
+
+type result[T any] struct {
+	v   T
+	err error
+}
+
+// within runs fn, returning a *fs.PathError wrapping ErrTimeout for op
+// on name if it hasn't returned after d.
+func within[T any](d time.Duration, op, name string, fn func() (T, error)) (T, error) {
+	done := make(chan result[T], 1)
+	go func() {
+		v, err := fn()
+		done <- result[T]{v, err}
+	}()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-t.C:
+		var zero T
+		return zero, &fs.PathError{Op: op, Path: name, Err: ErrTimeout}
+	}
+}
+
+func (t timeoutFS) Open(name string) (fs.File, error) {
+	f, err := within(t.d, "open", name, func() (fs.File, error) { return t.fsys.Open(name) })
+	if err != nil {
+		return nil, err
+	}
+	return timeoutFile{f, name, t.d}, nil
+}
+
+func (t timeoutFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := within(t.d, "readdir", name, func() ([]fs.DirEntry, error) { return t.fsys.ReadDir(name) })
+	return timeoutEntries(entries, t.d), err
+}
+
+func (t timeoutFS) ReadFile(name string) ([]byte, error) {
+	return within(t.d, "read", name, func() ([]byte, error) { return t.fsys.ReadFile(name) })
+}
+
+func (t timeoutFS) Stat(name string) (fs.FileInfo, error) {
+	return within(t.d, "stat", name, func() (fs.FileInfo, error) { return t.fsys.Stat(name) })
+}
+
+func (t timeoutFS) Lstat(name string) (fs.FileInfo, error) {
+	return within(t.d, "lstat", name, func() (fs.FileInfo, error) { return t.fsys.Lstat(name) })
+}
+
+func (t timeoutFS) ReadLink(name string) (string, error) {
+	return within(t.d, "readlink", name, func() (string, error) { return t.fsys.ReadLink(name) })
+}
+
+// timeoutFile bounds reads of a file or dir opened through timeoutFS.
+type timeoutFile struct {
+	fs.File
+	name string
+	d    time.Duration
+}
+
+func (f timeoutFile) Read(b []byte) (int, error) {
+	// A late read must not land in b after we've returned
+	buf := make([]byte, len(b))
+	n, err := within(f.d, "read", f.name, func() (int, error) { return f.File.Read(buf) })
+	copy(b, buf[:n])
+	return n, err
+}
+
+func (f timeoutFile) Seek(offset int64, whence int) (int64, error) {
+	s, ok := f.File.(io.Seeker)
+	if !ok {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: errors.ErrUnsupported}
+	}
+	return within(f.d, "seek", f.name, func() (int64, error) { return s.Seek(offset, whence) })
+}
+
+func (f timeoutFile) Stat() (fs.FileInfo, error) {
+	return within(f.d, "stat", f.name, f.File.Stat)
+}
+
+func (f timeoutFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	rd, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: errors.ErrUnsupported}
+	}
+	entries, err := within(f.d, "readdir", f.name, func() ([]fs.DirEntry, error) { return rd.ReadDir(n) })
+	return timeoutEntries(entries, f.d), err
+}
+
+// timeoutEntry bounds the lstat behind Info, which os makes lazily.
+type timeoutEntry struct {
+	fs.DirEntry
+	d time.Duration
+}
+
+func (e timeoutEntry) Info() (fs.FileInfo, error) {
+	return within(e.d, "lstat", e.Name(), e.DirEntry.Info)
+}
+
+func timeoutEntries(entries []fs.DirEntry, d time.Duration) []fs.DirEntry {
+	for i, e := range entries {
+		entries[i] = timeoutEntry{e, d}
+	}
+	return entries
+}
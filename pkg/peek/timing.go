@@ -0,0 +1,50 @@
+package peek
+
+import "time"
+
+// Phase is one kind of work a Scanner times.
+type Phase int
+
+const (
+	PhaseReadDir  Phase = iota // listing the dir itself
+	PhaseStat                  // stat of each entry
+	PhaseLinks                 // reading and resolving symlinks
+	PhaseCounts                // reading subdirs for their child counts
+	PhaseSizes                 // recursive sizes, for DiskUsage
+	PhaseAnnotate              // Annotate plugins
+	PhaseRender                // drawing, timed by the caller
+	numPhases
+)
+
+var phaseNames = [numPhases]string{"readdir", "stat", "links", "counts", "sizes", "plugins", "render"}
+
+func (p Phase) String() string {
+	return phaseNames[p]
+}
+
+// Timing totals the wall time spent per Phase. Set Scanner.Timing to
+// collect it; one Timing must not be shared by concurrent Scans.
+type Timing struct {
+	d [numPhases]time.Duration
+}
+
+// Add counts the time since start towards p. A nil Timing ignores it.
+func (t *Timing) Add(p Phase, start time.Time) {
+	if t != nil {
+		t.d[p] += time.Since(start)
+	}
+}
+
+// Get is the time counted towards p.
+func (t *Timing) Get(p Phase) time.Duration {
+	return t.d[p]
+}
+
+// Phases lists every Phase in the order work happens.
+func Phases() []Phase {
+	ps := make([]Phase, numPhases)
+	for i := range ps {
+		ps[i] = Phase(i)
+	}
+	return ps
+}
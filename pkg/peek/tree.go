@@ -0,0 +1,185 @@
+package peek
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Node is an Entry together with its scanned children.
+type Node struct {
+	Entry
+	Children []Node
+	Expanded bool // children were scanned; false at the depth limit
+}
+
+// Walk scans path recursively, descending at most depth levels
+// (0 for no limit). Symlinked and Pruned directories are listed but
+// not entered.
+// FilesOnly is ignored since the tree needs its directories.
+func (s *Scanner) Walk(dir string, depth int) ([]Node, error) {
+	sc := *s
+	sc.FilesOnly = false
+	fsys, name, err := sc.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := sc.walk(fsys, name, sc.ignores(dir, fsys, name), depth, 1)
+	if pe, ok := err.(*fs.PathError); ok && s.FS == nil {
+		pe.Path = dir
+	}
+	return nodes, err
+}
+
+func (s *Scanner) walk(fsys fs.FS, dir string, gi *gitIgnore, depth, level int) ([]Node, error) {
+	entries, _, err := s.scanFS(fsys, dir, gi)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, len(entries))
+	for i, e := range entries {
+		nodes[i].Entry = e
+		if !e.IsDir || e.IsSymlink || e.Pruned || (depth > 0 && level >= depth) {
+			continue
+		}
+		child := path.Join(dir, e.Name)
+		children, err := s.walk(fsys, child, gi.load(fsys, child), depth, level+1)
+		if err != nil {
+			continue
+		}
+		nodes[i].Children = children
+		nodes[i].Expanded = true
+	}
+	return nodes, nil
+}
+
+// TreeRenderer draws a walked tree with branch glyphs inside a box.
+type TreeRenderer struct {
+	Width int     // terminal columns; 80 when zero
+	Title string  // shown above the tree, usually the root path
+	Icons IconSet // glyph before each name
+
+	// Colors, when set, styles names by LS_COLORS rules instead of the theme
+	Colors *LSColors
+
+	// LinkDir, when set, is the absolute root dir; names become OSC 8
+	// hyperlinks to their files.
+	LinkDir string
+
+	// Classify marks executable files with a trailing "*", as ls -F does
+	Classify bool
+}
+
+func (r TreeRenderer) Render(w io.Writer, nodes []Node) error {
+	width := r.Width
+	if width <= 0 {
+		width = 80
+	}
+	inner := width - 2 // full width minus border
+	if inner < 20 {
+		inner = 20
+	}
+	lineWidth := inner - 4 // minus padding
+
+	var lines []string
+	var dirCount, fileCount int
+	var walk func(nodes []Node, indent, dir string)
+	walk = func(nodes []Node, indent, dir string) {
+		for i, n := range nodes {
+			branch, next := "├── ", "│   "
+			if i == len(nodes)-1 {
+				branch, next = "└── ", "    "
+			}
+			if n.IsDir {
+				dirCount++
+			} else {
+				fileCount++
+			}
+			lines = append(lines, r.line(n, indent+branch, dir, lineWidth))
+			if n.Expanded {
+				walk(n.Children, indent+next, path.Join(dir, n.Name))
+			}
+		}
+	}
+	walk(nodes, "", ".")
+
+	if len(lines) == 0 {
+		lines = append(lines, CountStyle.Render("empty"))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(boxBorder).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Width(inner)
+	body := makeHeader(Truncate(r.Title, lineWidth), lineWidth) + strings.Join(lines, "\n")
+	_, err := fmt.Fprintf(w, "\n%s\n\n%s\n\n", box.Render(body), Footer(dirCount, fileCount))
+	return err
+}
+
+// line renders n, found at dir relative to the root, below prefix.
+func (r TreeRenderer) line(n Node, prefix, dir string, lineWidth int) string {
+	// Expanded dirs show their children instead of a subtitle
+	meta := ""
+	switch {
+	case !n.IsDir || !n.Expanded:
+		meta = sizeLabel(n.Entry)
+	}
+
+	icon := r.Icons.Icon(n.Entry)
+	avail := lineWidth - textWidth(prefix)
+	if icon != "" {
+		avail -= textWidth(icon) + 1
+	}
+	nameLimit := avail
+	if meta != "" {
+		nameLimit = avail - textWidth(meta) - 3
+	}
+	if nameLimit < 8 {
+		nameLimit = 8
+	}
+	name := classified(n.Entry, nameLimit, r.Classify)
+
+	var styledName string
+	lsStyle, lsOK := r.Colors.Style(n.Entry)
+	switch {
+	case n.Pruned:
+		styledName = dotDirStyle.Render(name)
+	case lsOK:
+		styledName = lsStyle.Render(name)
+	case n.IsSymlink:
+		styledName = symNameStyle.Render(name)
+	case n.IsDir && n.Hidden:
+		styledName = dotDirStyle.Render(name)
+	case n.IsDir:
+		styledName = dirNameStyle.Render(name)
+	case n.Executable():
+		styledName = execNameStyle.Render(name)
+	case n.Hidden:
+		styledName = dotFileStyle.Render(name)
+	default:
+		styledName = fileNameStyle.Render(name)
+	}
+
+	if r.LinkDir != "" {
+		styledName = hyperlink(filepath.Join(r.LinkDir, filepath.FromSlash(dir)), n.Name, styledName)
+	}
+	line := dirIndicator.Render(prefix) + styledName
+	if icon != "" {
+		line = dirIndicator.Render(prefix) + metaStyle.Render(icon) + " " + styledName
+	}
+	if meta == "" {
+		return line
+	}
+	dots := avail - textWidth(name) - textWidth(meta)
+	if dots < 3 {
+		dots = 3
+	}
+	leader := " " + dotLeaderStyle.Render(strings.Repeat("·", dots-2)) + " "
+	return line + leader + metaStyle.Render(meta)
+}
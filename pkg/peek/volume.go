@@ -0,0 +1,50 @@
+package peek
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Volume is the filesystem a path lives on.
+type Volume struct {
+	Mount string // where it is mounted, or its root on Windows
+	Total uint64 // bytes
+	Free  uint64 // bytes free, including any reserved for root
+	Avail uint64 // bytes free to unprivileged users
+}
+
+// Used is the bytes in use.
+func (v Volume) Used() uint64 {
+	return v.Total - v.Free
+}
+
+// Percent is the share in use, as df counts it: of the space users can
+// get at, leaving out what is reserved.
+func (v Volume) Percent() float64 {
+	if v.Used()+v.Avail == 0 {
+		return 0
+	}
+	return 100 * float64(v.Used()) / float64(v.Used()+v.Avail)
+}
+
+// VolumeOf reports the volume holding the local path p.
+func VolumeOf(p string) (Volume, error) {
+	return volumeOf(p)
+}
+
+const volumeBarWidth = 20
+
+// VolumeHeader is a line for above a listing: v's mount, a usage bar,
+// and used and free space. The bar turns red past 90%.
+func VolumeHeader(v Volume) string {
+	pct := v.Percent()
+	used := min(int(pct/100*volumeBarWidth+0.5), volumeBarWidth)
+	barStyle := TitleStyle
+	if pct >= 90 {
+		barStyle = ErrStyle
+	}
+	bar := barStyle.Render(strings.Repeat("━", used)) + sepStyle.Render(strings.Repeat("─", volumeBarWidth-used))
+	text := fmt.Sprintf("%.0f%%  ·  %s used  ·  %s free of %s", pct,
+		HumanSize(int64(v.Used())), HumanSize(int64(v.Avail)), HumanSize(int64(v.Total)))
+	return "  " + TitleStyle.Render(Sanitize(v.Mount)) + "  " + bar + "  " + CountStyle.Render(text)
+}
@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || freebsd || dragonfly || windows)
+
+package peek
+
+import "errors"
+
+func volumeOf(string) (Volume, error) {
+	return Volume{}, errors.ErrUnsupported
+}
@@ -0,0 +1,56 @@
+//go:build linux || darwin || freebsd || dragonfly
+
+package peek
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func volumeOf(p string) (Volume, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(p, &st); err != nil {
+		return Volume{}, &os.PathError{Op: "statfs", Path: p, Err: err}
+	}
+	bsize := uint64(st.Bsize)
+	return Volume{
+		Mount: mountPoint(p),
+		Total: uint64(st.Blocks) * bsize,
+		Free:  uint64(st.Bfree) * bsize,
+		Avail: uint64(st.Bavail) * bsize,
+	}, nil
+}
+
+// mountPoint climbs from p while the parent is on the same device.
+func mountPoint(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	dev, ok := device(abs)
+	if !ok {
+		return abs
+	}
+	for {
+		parent := filepath.Dir(abs)
+		if d, ok := device(parent); parent == abs || !ok || d != dev {
+			return abs
+		}
+		abs = parent
+	}
+}
+
+func device(p string) (uint64, bool) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
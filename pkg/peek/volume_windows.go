@@ -0,0 +1,28 @@
+package peek
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+func volumeOf(p string) (Volume, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return Volume{}, err
+	}
+	name, err := windows.UTF16PtrFromString(abs)
+	if err != nil {
+		return Volume{}, err
+	}
+	root := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumePathName(name, &root[0], uint32(len(root))); err != nil {
+		return Volume{}, &os.PathError{Op: "GetVolumePathName", Path: p, Err: err}
+	}
+	var avail, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(&root[0], &avail, &total, &free); err != nil {
+		return Volume{}, &os.PathError{Op: "GetDiskFreeSpaceEx", Path: p, Err: err}
+	}
+	return Volume{Mount: windows.UTF16ToString(root), Total: total, Free: free, Avail: avail}, nil
+}
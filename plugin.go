@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// How long a plugin may take per entry, and how much of its output is kept
+const (
+	pluginTimeout = 2 * time.Second
+	maxNoteLen    = 24
+)
+
+// annotator runs each plugin command with the entry's path appended as
+// its last argument and joins the first lines of their output into a
+// note. A plugin that fails, times out or prints nothing adds nothing.
+func annotator(plugins []string) func(string, peek.Entry) string {
+	var cmds [][]string
+	for _, p := range plugins {
+		if f := strings.Fields(p); len(f) > 0 {
+			cmds = append(cmds, f)
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return func(path string, _ peek.Entry) string {
+		var notes []string
+		for _, argv := range cmds {
+			ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+			out, err := exec.CommandContext(ctx, argv[0], append(argv[1:], path)...).Output()
+			cancel()
+			if err != nil {
+				continue
+			}
+			line, _, _ := strings.Cut(string(bytes.TrimSpace(out)), "\n")
+			if line = strings.TrimSpace(line); line != "" {
+				notes = append(notes, peek.Truncate(line, maxNoteLen))
+			}
+		}
+		return strings.Join(notes, "  ")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+	"golang.org/x/term"
+)
+
+// Scans quicker than this finish without a progress line.
+const progressDelay = 300 * time.Millisecond
+
+var spinner = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// showProgress draws a spinner and the entries p has counted on stderr
+// once a scan has run for progressDelay, until the returned func is
+// called, which clears the line. Nothing is drawn unless stderr is a
+// terminal.
+func showProgress(p *peek.Progress) (done func()) {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return func() {}
+	}
+	base := p.Entries()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-stop:
+			return
+		case <-time.After(progressDelay):
+		}
+		tick := time.NewTicker(100 * time.Millisecond)
+		defer tick.Stop()
+		for i := 0; ; i++ {
+			line := fmt.Sprintf("%s scanning… %d entries", spinner[i%len(spinner)], p.Entries()-base)
+			fmt.Fprint(os.Stderr, "\r\x1b[K"+peek.CountStyle.Render(line))
+			select {
+			case <-stop:
+				fmt.Fprint(os.Stderr, "\r\x1b[K")
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// parseS3 splits s3://bucket/prefix into the bucket and an fs path.
+func parseS3(arg string) (bucket, dir string, ok bool) {
+	rest, ok := strings.CutPrefix(arg, "s3://")
+	if !ok {
+		return "", "", false
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", false
+	}
+	dir = strings.Trim(prefix, "/")
+	if dir == "" {
+		dir = "."
+	}
+	return bucket, dir, true
+}
+
+// newS3FS uses the standard AWS credential chain, region and endpoint
+// settings (AWS_PROFILE, AWS_REGION, AWS_ENDPOINT_URL, ...).
+func newS3FS(bucket string) (s3FS, error) {
+	ctx := context.Background()
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return s3FS{}, err
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return s3FS{ctx: ctx, client: s3.NewFromConfig(cfg), bucket: bucket}, nil
+}
+
+// s3FS lists a bucket as an fs.FS, splitting keys on "/". Common
+// prefixes become dirs and objects become files; objects can be listed
+// and statted but not read.
+type s3FS struct {
+	ctx    context.Context
+	client *s3.Client
+	bucket string
+}
+
+func (f s3FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	pages := s3.NewListObjectsV2Paginator(f.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(f.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+	var entries []fs.DirEntry
+	marker := false // a "name/" placeholder object marks an empty dir
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(f.ctx)
+		if err != nil {
+			return nil, s3Err("readdir", name, err)
+		}
+		for _, cp := range page.CommonPrefixes {
+			child := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(cp.Prefix), prefix), "/")
+			if fs.ValidPath(child) && !strings.Contains(child, "/") {
+				entries = append(entries, fs.FileInfoToDirEntry(s3Info{name: child, dir: true}))
+			}
+		}
+		for _, obj := range page.Contents {
+			child := strings.TrimPrefix(aws.ToString(obj.Key), prefix)
+			if child == "" {
+				marker = true
+				continue
+			}
+			if fs.ValidPath(child) && !strings.Contains(child, "/") {
+				entries = append(entries, fs.FileInfoToDirEntry(s3Info{
+					name:    child,
+					size:    aws.ToInt64(obj.Size),
+					modTime: aws.ToTime(obj.LastModified),
+				}))
+			}
+		}
+	}
+	if len(entries) == 0 && name != "." && !marker {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (f s3FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return s3Info{name: ".", dir: true}, nil
+	}
+	head, err := f.client.HeadObject(f.ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(f.bucket),
+		Key:    aws.String(name),
+	})
+	if err == nil {
+		return s3Info{name: name[strings.LastIndex(name, "/")+1:], size: aws.ToInt64(head.ContentLength), modTime: aws.ToTime(head.LastModified)}, nil
+	}
+	// No object by that key: it is a dir if anything lives below it
+	list, err := f.client.ListObjectsV2(f.ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(f.bucket),
+		Prefix:  aws.String(name + "/"),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, s3Err("stat", name, err)
+	}
+	if len(list.Contents) == 0 {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return s3Info{name: name[strings.LastIndex(name, "/")+1:], dir: true}, nil
+}
+
+func (f s3FS) Open(name string) (fs.File, error) {
+	info, err := f.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return s3File{info}, nil
+}
+
+func s3Err(op, name string, err error) error {
+	var noBucket *types.NoSuchBucket
+	if errors.As(err, &noBucket) {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+type s3File struct {
+	info fs.FileInfo
+}
+
+func (f s3File) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f s3File) Close() error               { return nil }
+
+func (f s3File) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: f.info.Name(), Err: errors.ErrUnsupported}
+}
+
+type s3Info struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+func (i s3Info) Name() string       { return i.name }
+func (i s3Info) Size() int64        { return i.size }
+func (i s3Info) ModTime() time.Time { return i.modTime }
+func (i s3Info) IsDir() bool        { return i.dir }
+func (i s3Info) Sys() any           { return nil }
+
+func (i s3Info) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
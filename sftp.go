@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/term"
+)
+
+// remoteTarget is a parsed user@host:path or sftp://user@host:port/path.
+type remoteTarget struct {
+	user, host, port, path string
+}
+
+// login is "user@host", or just the host when no user was given.
+func (rt remoteTarget) login() string {
+	if rt.user != "" {
+		return rt.user + "@" + rt.host
+	}
+	return rt.host
+}
+
+func (rt remoteTarget) String() string {
+	return rt.login() + ":" + rt.path
+}
+
+// parseRemote recognises scp-style and URL targets. Local paths win:
+// anything that exists on disk, has a slash before the first colon, or
+// looks like a Windows drive letter is not remote.
+func parseRemote(arg string) (remoteTarget, bool) {
+	if strings.HasPrefix(arg, "ssh://") || strings.HasPrefix(arg, "sftp://") {
+		u, err := url.Parse(arg)
+		if err != nil || u.Hostname() == "" {
+			return remoteTarget{}, false
+		}
+		return remoteTarget{user: u.User.Username(), host: u.Hostname(), port: u.Port(), path: u.Path}, true
+	}
+
+	hostPart, p, ok := strings.Cut(arg, ":")
+	if !ok || strings.ContainsAny(hostPart, `/\`) {
+		return remoteTarget{}, false
+	}
+	if _, err := os.Lstat(arg); err == nil {
+		return remoteTarget{}, false
+	}
+	rt := remoteTarget{host: hostPart, path: p}
+	if u, h, ok := strings.Cut(hostPart, "@"); ok {
+		rt.user, rt.host = u, h
+	}
+	if len(rt.host) < 2 {
+		return remoteTarget{}, false
+	}
+	return rt, true
+}
+
+func dialSFTP(rt remoteTarget) (*sftp.Client, func(), error) {
+	name := rt.user
+	if name == "" {
+		if u, err := user.Current(); err == nil {
+			name = u.Username
+		}
+	}
+	home, _ := os.UserHomeDir()
+
+	hostKeys, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading known_hosts (connect once with ssh first): %w", err)
+	}
+
+	cfg := &ssh.ClientConfig{
+		User:            name,
+		Auth:            sshAuth(home, rt),
+		HostKeyCallback: hostKeys,
+	}
+	port := rt.port
+	if port == "" {
+		port = "22"
+	}
+	conn, err := ssh.Dial("tcp", net.JoinHostPort(rt.host, port), cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	// Dropping the connection first ends the session even if the
+	// server never closes its side of the subsystem channel
+	return client, func() {
+		conn.Close()
+		client.Close()
+	}, nil
+}
+
+// sshAuth tries the agent, then unencrypted default keys, then a password
+// prompt when stdin is a terminal.
+func sshAuth(home string, rt remoteTarget) []ssh.AuthMethod {
+	var methods []ssh.AuthMethod
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	var signers []ssh.Signer
+	for _, key := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		pem, err := os.ReadFile(filepath.Join(home, ".ssh", key))
+		if err != nil {
+			continue
+		}
+		if signer, err := ssh.ParsePrivateKey(pem); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		methods = append(methods, ssh.PasswordCallback(func() (string, error) {
+			fmt.Fprintf(os.Stderr, "%s's password: ", rt)
+			pw, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(os.Stderr)
+			return string(pw), err
+		}))
+	}
+	return methods
+}
+
+// sftpFS exposes the whole remote filesystem as an fs.FS: fs path "a/b"
+// is remote "/a/b" and "." is "/".
+type sftpFS struct {
+	c *sftp.Client
+}
+
+func sftpName(abs string) string {
+	name := strings.Trim(path.Clean("/"+abs), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (f sftpFS) abs(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return "/", nil
+	}
+	return "/" + name, nil
+}
+
+// fsErr maps sftp status errors onto the fs sentinel errors.
+func fsErr(op, name string, err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		err = fs.ErrNotExist
+	} else if errors.Is(err, os.ErrPermission) {
+		err = fs.ErrPermission
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+func (f sftpFS) Open(name string) (fs.File, error) {
+	p, err := f.abs("open", name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := f.c.Open(p)
+	if err != nil {
+		return nil, fsErr("open", name, err)
+	}
+	return file, nil
+}
+
+func (f sftpFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := f.abs("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := f.c.ReadDir(p)
+	if err != nil {
+		return nil, fsErr("readdir", name, err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, nil
+}
+
+func (f sftpFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := f.abs("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.c.Stat(p)
+	if err != nil {
+		return nil, fsErr("stat", name, err)
+	}
+	return info, nil
+}
+
+func (f sftpFS) Lstat(name string) (fs.FileInfo, error) {
+	p, err := f.abs("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.c.Lstat(p)
+	if err != nil {
+		return nil, fsErr("lstat", name, err)
+	}
+	return info, nil
+}
+
+func (f sftpFS) ReadLink(name string) (string, error) {
+	p, err := f.abs("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	target, err := f.c.ReadLink(p)
+	if err != nil {
+		return "", fsErr("readlink", name, err)
+	}
+	return target, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import "fmt"
+
+// Shell functions wrapping peek so that quitting peek -i with q leaves
+// the shell in the dir it was showing. peek tells them the dir through
+// the file named by $PEEK_PRINT_DIR, which leaves its output alone.
+var shellInits = map[string]string{
+	"bash": posixInit,
+	"zsh":  posixInit,
+	"fish": `function peek --wraps peek --description 'peek; quitting peek -i cds to its dir'
+    set -l dir_file (mktemp)
+    or return
+    PEEK_PRINT_DIR=$dir_file command peek $argv
+    set -l peek_status $status
+    if test -s $dir_file
+        cd (cat $dir_file)
+    end
+    rm -f $dir_file
+    return $peek_status
+end
+`,
+	"powershell": `function peek {
+    $dirFile = New-TemporaryFile
+    $env:PEEK_PRINT_DIR = $dirFile.FullName
+    try {
+        & (Get-Command peek -CommandType Application | Select-Object -First 1) @args
+    } finally {
+        Remove-Item Env:PEEK_PRINT_DIR
+    }
+    $dir = Get-Content -Raw -LiteralPath $dirFile.FullName
+    Remove-Item -LiteralPath $dirFile.FullName
+    if ($dir) { Set-Location -LiteralPath $dir }
+}
+`,
+}
+
+const posixInit = `peek() {
+  local __peek_file __peek_status
+  __peek_file="$(mktemp "${TMPDIR:-/tmp}/peek-dir.XXXXXX")" || return
+  PEEK_PRINT_DIR="$__peek_file" command peek "$@"
+  __peek_status=$?
+  if [ -s "$__peek_file" ]; then
+    cd -- "$(cat -- "$__peek_file")" || __peek_status=$?
+  fi
+  rm -f -- "$__peek_file"
+  return "$__peek_status"
+}
+`
+
+// runShellInit handles `peek shell-init SHELL`.
+func runShellInit(args []string) {
+	fl := newFlagSet("peek shell-init bash|zsh|fish|powershell")
+	fl.intro = []string{
+		"Prints a peek function for your shell's startup file; with it,",
+		"quitting peek -i with q cds the shell to the dir you were in:",
+		`  eval "$(peek shell-init bash)"            # ~/.bashrc, or zsh in ~/.zshrc`,
+		"  peek shell-init fish | source              # ~/.config/fish/config.fish",
+		"  peek shell-init powershell | Out-String | Invoke-Expression  # $PROFILE",
+	}
+	pos := fl.parse(args)
+	if len(pos) != 1 {
+		fatal(fmt.Errorf("usage: peek shell-init bash|zsh|fish|powershell"))
+	}
+	script, ok := shellInits[pos[0]]
+	if !ok {
+		fatal(fmt.Errorf("unknown shell %q (bash, zsh, fish, powershell)", pos[0]))
+	}
+	fmt.Print(script)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runSnapshot handles `peek snapshot save|diff [options] FILE [path]`.
+func runSnapshot(args []string) {
+	showAll := false
+	icons := peek.NoIcons
+
+	fl := newFlagSet("peek snapshot save [options] FILE [path]", "peek snapshot diff [options] FILE [path]")
+	fl.bool(&showAll, "a", "all", "include hidden files")
+	fl.optional("icons", "SET", "file icons: nerd (default) or emoji", func(v string) { icons = parseIcons(v) })
+	pos := fl.parse(args)
+	if len(pos) < 2 || len(pos) > 3 || pos[0] != "save" && pos[0] != "diff" {
+		fatal(fmt.Errorf("usage: peek snapshot save|diff FILE [path]"))
+	}
+	action, file := pos[0], pos[1]
+
+	applyTheme()
+	scanner := &peek.Scanner{ShowAll: showAll}
+
+	if action == "save" {
+		target := "."
+		if len(pos) == 3 {
+			target = pos[2]
+		}
+		entries, label := scanTarget(scanner, target)
+		f, err := os.Create(file)
+		if err != nil {
+			fatal(err)
+		}
+		if err := peek.NewSnapshot(label, entries).Write(f); err != nil {
+			f.Close()
+			fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			fatal(err)
+		}
+		fmt.Printf("  %s\n", peek.CountStyle.Render(fmt.Sprintf("saved %d entries of %s to %s", len(entries), label, file)))
+		return
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		fatal(err)
+	}
+	snap, err := peek.ReadSnapshot(f)
+	f.Close()
+	if err != nil {
+		fatal(err)
+	}
+	target := snap.Path
+	if len(pos) == 3 {
+		target = pos[2]
+	}
+	entries, _ := scanTarget(scanner, target)
+	fmt.Println("\n  " + peek.TitleStyle.Render(target))
+	r := peek.PanelRenderer{Width: termWidth(), Icons: icons}
+	fmt.Printf("\n%s\n\n", r.SnapshotDiff(snap.Compare(entries), snap.Taken))
+}
+
+// scanTarget lists target and returns it with the name to record it
+// under: an absolute path locally, the argument as given remotely.
+func scanTarget(scanner *peek.Scanner, target string) ([]peek.Entry, string) {
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	entries, err := loc.scanner.Scan(loc.dir)
+	err = loc.fail(err)
+	loc.Close()
+	if err != nil {
+		fatal(err)
+	}
+	label := target
+	if !loc.remote() {
+		if abs, err := filepath.Abs(target); err == nil {
+			label = abs
+		}
+	}
+	return entries, label
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+// runStats handles `peek stats [options] [path]`.
+func runStats(args []string) {
+	showAll := false
+	var match []string
+
+	fl := newFlagSet("peek stats [options] [path]")
+	fl.bool(&showAll, "a", "all", "count hidden files")
+	fl.value("", "match", "GLOB", "only files matching GLOB (repeatable)", func(v string) { match = append(match, parseGlob(v)) })
+	target := onePath(fl.parse(args))
+
+	applyTheme()
+	scanner := &peek.Scanner{ShowAll: showAll, Match: match}
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	stats, err := loc.scanner.Stats(loc.dir)
+	err = loc.fail(err)
+	loc.Close()
+	if err != nil {
+		fatal(err)
+	}
+	r := peek.StatsRenderer{Width: termWidth(), Title: target}
+	if err := r.Render(os.Stdout, stats); err != nil {
+		fatal(err)
+	}
+}
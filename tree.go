@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+)
+
+const defaultTreeDepth = 2
+
+// runTree handles `peek tree [options] [path]`.
+func runTree(args []string) {
+	showAll := false
+	gitIgnore := false
+	prune := false
+	useLSColors := false
+	hyperlinks := false
+	classify := false
+	depth := defaultTreeDepth
+	icons := peek.NoIcons
+
+	fl := newFlagSet("peek tree [options] [path]")
+	fl.bool(&showAll, "a", "all", "show hidden files")
+	fl.value("d", "depth", "N", "levels to descend, 0 for all (default 2)", func(v string) { depth = parseDepth(v) })
+	fl.bool(&gitIgnore, "", "git-ignore", "hide entries matched by .gitignore")
+	fl.bool(&prune, "", "prune-common", "don't descend into node_modules, .git, target and such")
+	fl.optional("icons", "SET", "file icons: nerd (default) or emoji", func(v string) { icons = parseIcons(v) })
+	fl.bool(&useLSColors, "", "ls-colors", "color names by $LS_COLORS")
+	fl.bool(&hyperlinks, "", "hyperlinks", "names link to their files (OSC 8)")
+	fl.bool(&classify, "F", "classify", "mark executables with *")
+	target := onePath(fl.parse(args))
+
+	applyTheme()
+	scanner := &peek.Scanner{ShowAll: showAll, GitIgnore: gitIgnore}
+	if prune {
+		cfg, _ := loadConfig()
+		scanner.Prune = pruneNames(cfg)
+	}
+	loc, err := locate(scanner, target)
+	if err != nil {
+		fatal(err)
+	}
+	nodes, err := loc.scanner.Walk(loc.dir, depth)
+	err = loc.fail(err)
+	loc.Close()
+	if err != nil {
+		fatal(err)
+	}
+	r := peek.TreeRenderer{
+		Width:    termWidth(),
+		Title:    target,
+		Icons:    icons,
+		Colors:   lsColors(useLSColors),
+		LinkDir:  loc.linkDir(hyperlinks, loc.dir),
+		Classify: classify,
+	}
+	if err := r.Render(os.Stdout, nodes); err != nil {
+		fatal(err)
+	}
+}
+
+func parseDepth(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		fatal(fmt.Errorf("invalid depth %q", s))
+	}
+	return n
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Lines the interactive view spends outside the entry rows:
+// path, box border and padding, panel header, footer, help.
+const tuiChrome = 10
+
+type model struct {
+	loc      *location
+	dir      string // OS path, or fs path when remote
+	scanner  *peek.Scanner
+	renderer peek.PanelRenderer
+	links    bool // hyperlink names, where the dir is a local one
+	zoxide   bool // rank each local dir opened up in zoxide
+
+	dirs, files []peek.Entry
+	cursor      int
+	dirOff      int
+	fileOff     int
+
+	// Miller columns: the parent dir, this one, and the selection
+	miller        bool
+	parentEntries []peek.Entry
+	parentAt      int          // this dir's index in parentEntries
+	child         []peek.Entry // entries of the selected dir or archive
+	text          []string     // or the first lines of the selected file
+	hex           []byte       // or, when it is binary, its first bytes
+	img           image.Image  // or the picture, when it is an image
+	readme        string       // the selected dir's README, if it has one
+	readmeLines   []string     // and its first lines
+	off           int          // scroll of this dir's single list
+
+	height int
+	err    error
+	quit   bool // left with q or esc, rather than ctrl+c
+}
+
+// runInteractive browses target until quit, and returns the local dir
+// it was left in with q or esc, for --print-dir; "" otherwise.
+func runInteractive(target string, scanner *peek.Scanner, renderer peek.PanelRenderer, links, zoxide bool) (string, error) {
+	loc, err := locate(scanner, target)
+	if err != nil {
+		return "", err
+	}
+	defer loc.Close()
+
+	m := &model{loc: loc, scanner: loc.scanner, dir: loc.dir, renderer: renderer, links: links, zoxide: zoxide}
+	m.renderer.Width = termWidth()
+	if !loc.remote() {
+		if m.dir, err = filepath.Abs(target); err != nil {
+			return "", err
+		}
+	}
+	if err := m.load(""); err != nil {
+		return "", loc.fail(err)
+	}
+	if _, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(screen)).Run(); err != nil || !m.quit || loc.remote() {
+		return "", err
+	}
+	return realDir(m.dir), nil
+}
+
+// realDir is dir, or when it runs through an archive, the dir holding
+// the archive: somewhere a shell can cd to.
+func realDir(dir string) string {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// join, parent and base work on OS paths locally and fs paths remotely.
+func (m *model) join(name string) string {
+	if m.loc.remote() {
+		return path.Join(m.dir, name)
+	}
+	return filepath.Join(m.dir, name)
+}
+
+func (m *model) parent() string {
+	if m.loc.remote() {
+		return path.Dir(m.dir)
+	}
+	return filepath.Dir(m.dir)
+}
+
+func (m *model) parentBase() string {
+	if m.loc.remote() {
+		return path.Base(m.parent())
+	}
+	return filepath.Base(m.parent())
+}
+
+func (m *model) base() string {
+	if m.loc.remote() {
+		return path.Base(m.dir)
+	}
+	return filepath.Base(m.dir)
+}
+
+// load rescans m.dir and puts the cursor on the entry named focus, if any.
+func (m *model) load(focus string) error {
+	entries, hidden, err := m.scanner.ScanHidden(m.dir)
+	if err != nil {
+		return err
+	}
+	m.renderer.Hidden = hidden
+	if m.zoxide && !m.loc.remote() {
+		zoxideAdd(m.dir)
+	}
+	dirs, files := peek.Split(entries)
+	m.dirs, m.files = dirs, files
+	m.renderer.LinkDir = m.loc.linkDir(m.links, m.dir)
+	m.cursor, m.dirOff, m.fileOff, m.off = 0, 0, 0, 0
+	for i, e := range entries {
+		if e.Name == focus {
+			m.cursor = i
+		}
+	}
+	m.loadParent()
+	m.scroll()
+	m.loadSelection()
+	return nil
+}
+
+// loadParent lists the parent dir for the left Miller column.
+func (m *model) loadParent() {
+	m.parentEntries, m.parentAt = nil, -1
+	if !m.miller || m.parent() == m.dir {
+		return
+	}
+	entries, err := m.scanner.Scan(m.parent())
+	if err != nil {
+		return
+	}
+	dirs, files := peek.Split(entries)
+	m.parentEntries = append(dirs, files...)
+	for i, e := range m.parentEntries {
+		if e.Name == m.base() {
+			m.parentAt = i
+		}
+	}
+}
+
+// loadSelection fills the right Miller column from the entry under the
+// cursor: a dir's or archive's entries, or a text file's first lines.
+func (m *model) loadSelection() {
+	m.child, m.text, m.hex, m.img = nil, nil, nil, nil
+	m.readme, m.readmeLines = "", nil
+	e, ok := m.selected()
+	if !m.miller || !ok {
+		return
+	}
+	if e.IsDir || peek.IsArchive(e.Name) {
+		entries, _ := m.scanner.Scan(m.join(e.Name))
+		dirs, files := peek.Split(entries)
+		m.child = append(append([]peek.Entry{}, dirs...), files...)
+		if e.IsDir {
+			m.readme, m.readmeLines = m.scanner.ReadmePreview(m.join(e.Name), m.child, readmeLines)
+		}
+		return
+	}
+	if peek.KindOf(e) == peek.KindImage {
+		// Decoded whole, as stdlib decoders go, if not too large; blocks
+		// are the only graphics that survive the view's redraws
+		if src, _, err := m.scanner.ReadHead(m.dir, e.Name, maxImage); err == nil {
+			if m.img, _, err = peek.DecodeImage(src); err == nil {
+				return
+			}
+		}
+	}
+	m.text = m.scanner.PreviewFile(m.dir, e.Name, m.rows())
+	if m.text == nil {
+		// 16 bytes a line at most
+		m.hex, _, _ = m.scanner.ReadHead(m.dir, e.Name, int64(m.rows()*16))
+	}
+}
+
+func (m *model) selected() (peek.Entry, bool) {
+	if m.cursor < len(m.dirs) {
+		return m.dirs[m.cursor], true
+	}
+	if i := m.cursor - len(m.dirs); i < len(m.files) {
+		return m.files[i], true
+	}
+	return peek.Entry{}, false
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.renderer.Width, m.height = msg.Width, msg.Height
+		m.scroll()
+	case tea.KeyMsg:
+		m.err = nil
+		switch msg.String() {
+		case "q", "esc":
+			m.quit = true
+			return m, tea.Quit
+		case "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.dirs)+len(m.files)-1 {
+				m.cursor++
+			}
+		case "home", "g":
+			m.cursor = 0
+		case "end", "G":
+			m.cursor = max(len(m.dirs)+len(m.files)-1, 0)
+		case "m":
+			m.miller = !m.miller
+			m.loadParent()
+		case "o":
+			if e, ok := m.selected(); ok && m.loc.remote() {
+				m.err = fmt.Errorf("%s is remote; only local entries open", e.Name)
+			} else if ok {
+				m.err = openDefault(m.join(e.Name))
+			}
+		case "enter", "right", "l":
+			// Dirs and archives can be entered
+			name := ""
+			if m.cursor < len(m.dirs) {
+				name = m.dirs[m.cursor].Name
+			} else if i := m.cursor - len(m.dirs); i < len(m.files) && peek.IsArchive(m.files[i].Name) {
+				name = m.files[i].Name
+			}
+			if name != "" {
+				prev := m.dir
+				m.dir = m.join(name)
+				if err := m.load(""); err != nil {
+					m.dir, m.err = prev, err
+				}
+			}
+		case "backspace", "left", "h":
+			parent := m.parent()
+			if parent != m.dir {
+				prev, name := m.dir, m.base()
+				m.dir = parent
+				if err := m.load(name); err != nil {
+					m.dir, m.err = prev, err
+				}
+			}
+		}
+		m.scroll()
+		m.loadSelection()
+	}
+	return m, nil
+}
+
+// rows is how many entries fit in a panel at the current height.
+func (m *model) rows() int {
+	if m.height == 0 {
+		return len(m.dirs) + len(m.files)
+	}
+	if m.renderer.Stacked() && !m.miller {
+		// The boxes share the height
+		return max((m.height-tuiChrome-6)/2, 1)
+	}
+	return max(m.height-tuiChrome, 1)
+}
+
+// scroll keeps the cursor inside the visible window of its panel.
+func (m *model) scroll() {
+	rows := m.rows()
+	if m.miller {
+		m.off = clampOffset(m.off, m.cursor, rows)
+		return
+	}
+	if m.cursor < len(m.dirs) {
+		m.dirOff = clampOffset(m.dirOff, m.cursor, rows)
+	} else {
+		m.fileOff = clampOffset(m.fileOff, m.cursor-len(m.dirs), rows)
+	}
+}
+
+func clampOffset(off, cursor, rows int) int {
+	if cursor < off {
+		return cursor
+	}
+	if cursor >= off+rows {
+		return cursor - rows + 1
+	}
+	return off
+}
+
+func window(items []peek.Entry, off, rows int) []peek.Entry {
+	end := min(off+rows, len(items))
+	return items[off:end]
+}
+
+func (m *model) View() string {
+	out := "\n  " + peek.Breadcrumb(m.loc.title(m.dir), m.renderer.Width-4) + "\n"
+
+	if m.miller {
+		out += m.columns() + "\n"
+		out += m.renderer.Footer(m.dirs, m.files) + "\n"
+	} else if len(m.dirs) == 0 && len(m.files) == 0 {
+		out += "\n" + peek.CountStyle.Render("  empty") + "\n"
+	} else {
+		rows := m.rows()
+		dirs := window(m.dirs, m.dirOff, rows)
+		files := window(m.files, m.fileOff, rows)
+		sel := m.cursor - m.dirOff
+		if m.cursor >= len(m.dirs) {
+			sel = len(dirs) + m.cursor - len(m.dirs) - m.fileOff
+		}
+		out += m.renderer.Panels(dirs, files, sel) + "\n"
+		out += m.renderer.Footer(m.dirs, m.files) + "\n"
+	}
+
+	if m.err != nil {
+		out += "  " + peek.ErrStyle.Render("error: "+peek.Sanitize(m.err.Error())) + "\n"
+	}
+	out += "  " + peek.CountStyle.Render("↑/↓ move  ·  enter open  ·  backspace up  ·  m columns  ·  o launch  ·  q quit")
+	return out
+}
+
+// columns draws the parent dir, this one and the selection side by side.
+func (m *model) columns() string {
+	rows := m.rows()
+	r := m.renderer
+	r.Width = (m.renderer.Width - 2) / 3
+
+	parentOff := 0
+	if m.parentAt >= 0 {
+		parentOff = clampOffset(0, m.parentAt, rows)
+	}
+	left := r.Column(m.parentBase(), window(m.parentEntries, parentOff, rows), m.parentAt-parentOff)
+
+	entries := append(m.dirs[:len(m.dirs):len(m.dirs)], m.files...)
+	middle := r.Column(m.base(), window(entries, m.off, rows), m.cursor-m.off)
+
+	right := r.TextColumn("", nil)
+	if e, ok := m.selected(); ok && m.child != nil {
+		// The README goes under the entries when both fit, each box
+		// costing its border, padding and header
+		if childRows := rows - len(m.readmeLines) - 6; m.readme != "" && childRows >= 3 {
+			right = lipgloss.JoinVertical(lipgloss.Left,
+				r.Column(e.Name, window(m.child, 0, childRows), -1),
+				r.TextColumn(m.readme, m.readmeLines))
+		} else {
+			right = r.Column(e.Name, window(m.child, 0, rows), -1)
+		}
+	} else if ok && m.img != nil {
+		right = r.ImageColumn(e.Name, m.img, rows)
+	} else if ok && len(m.hex) > 0 {
+		right = r.HexColumn(e.Name, m.hex, rows)
+	} else if ok {
+		right = r.TextColumn(e.Name, m.text)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, " ", middle, " ", right)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/AlexandrosLiaskos/peek/pkg/peek"
+	"golang.org/x/mod/semver"
+)
+
+// releaseURL is the GitHub API endpoint for the newest release. Its
+// assets are named like peek_linux_amd64 and peek_windows_amd64.exe,
+// with their sha256sums in SHA256SUMS.
+var releaseURL = "https://api.github.com/repos/AlexandrosLiaskos/peek/releases/latest"
+
+type release struct {
+	Tag    string `json:"tag_name"`
+	Assets []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+var httpClient = &http.Client{Timeout: 2 * time.Minute}
+
+// runUpgrade handles `peek upgrade [--check]`.
+func runUpgrade(args []string) {
+	check := false
+
+	fl := newFlagSet("peek upgrade [options]")
+	fl.bool(&check, "", "check", "only report whether a newer release exists")
+	if pos := fl.parse(args); len(pos) > 0 {
+		fatal(fmt.Errorf("upgrade takes no arguments"))
+	}
+	applyTheme()
+
+	rel, err := latestRelease()
+	if err != nil {
+		fatal(err)
+	}
+	current := buildVersion()
+	latest, this := semverOf(rel.Tag), semverOf(current)
+	if !semver.IsValid(latest) {
+		fatal(fmt.Errorf("latest release has tag %q, not a version", rel.Tag))
+	}
+	if !semver.IsValid(this) {
+		// A dev or source build: there's no telling which is newer
+		fmt.Println("  " + peek.CountStyle.Render(fmt.Sprintf("peek %s isn't a release build; the latest release is %s", current, rel.Tag)))
+		return
+	}
+	if semver.Compare(this, latest) >= 0 {
+		fmt.Println("  " + peek.CountStyle.Render("peek "+current+" is the latest release"))
+		return
+	}
+	if check {
+		fmt.Println("  " + peek.TitleStyle.Render(fmt.Sprintf("peek %s is available (this is %s)", rel.Tag, current)))
+		return
+	}
+
+	asset := "peek_" + runtime.GOOS + "_" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		asset += ".exe"
+	}
+	bin, err := rel.download(asset)
+	if err != nil {
+		fatal(err)
+	}
+	sums, err := rel.download("SHA256SUMS")
+	if err != nil {
+		fatal(err)
+	}
+	if err := checkSum(sums, asset, bin); err != nil {
+		fatal(err)
+	}
+	if err := replaceExecutable(bin); err != nil {
+		fatal(err)
+	}
+	fmt.Println("  " + peek.CountStyle.Render(fmt.Sprintf("upgraded peek %s to %s", current, rel.Tag)))
+}
+
+func latestRelease() (release, error) {
+	var rel release
+	req, err := http.NewRequest("GET", releaseURL, nil)
+	if err != nil {
+		return rel, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return rel, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return rel, fmt.Errorf("checking for releases: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return rel, fmt.Errorf("checking for releases: %w", err)
+	}
+	return rel, nil
+}
+
+// semverOf gives v the "v" prefix semver wants; release tags have it
+// but versions set through ldflags may not.
+func semverOf(v string) string {
+	if !strings.HasPrefix(v, "v") {
+		return "v" + v
+	}
+	return v
+}
+
+// download fetches the release asset called name.
+func (r release) download(name string) ([]byte, error) {
+	for _, a := range r.Assets {
+		if a.Name != name {
+			continue
+		}
+		resp, err := httpClient.Get(a.URL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("downloading %s: %s", name, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	return nil, fmt.Errorf("release %s has no %s", r.Tag, name)
+}
+
+// checkSum verifies data against name's line in a sha256sum listing.
+func checkSum(sums []byte, name string, data []byte) error {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		sum, file, ok := strings.Cut(sc.Text(), "  ")
+		if !ok || strings.TrimPrefix(file, "*") != name {
+			continue
+		}
+		got := sha256.Sum256(data)
+		if hex.EncodeToString(got[:]) != strings.ToLower(sum) {
+			return fmt.Errorf("%s: checksum mismatch, not installing", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("SHA256SUMS has no entry for %s", name)
+}
+
+// replaceExecutable swaps the running binary for bin. The new file is
+// written beside the old one and renamed over it; Windows can't replace
+// a running exe, but can rename it, so the old one is moved aside
+// first, put back if the swap fails, and removed on the next run.
+func replaceExecutable(bin []byte) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".peek-upgrade-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(bin); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), exe); err != nil {
+			os.Rename(old, exe)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), exe)
+}
+
+// removeOldExecutable deletes the exe a Windows upgrade moved aside,
+// which couldn't be removed while it was still running.
+func removeOldExecutable() {
+	if runtime.GOOS != "windows" {
+		return
+	}
+	if exe, err := os.Executable(); err == nil {
+		if exe, err = filepath.EvalSymlinks(exe); err == nil {
+			os.Remove(exe + ".old")
+		}
+	}
+}
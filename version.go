@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at release time with
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.date=$(date -u +%F)"
+//
+// Builds without them fall back to what the Go toolchain recorded.
+var (
+	version = ""
+	commit  = ""
+	date    = ""
+)
+
+// buildVersion is the release version, the module version for
+// `go install ...@v1.2.0`, or "dev".
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// versionString is the --version line, e.g.
+// "peek v1.2.0 (abc1234, 2026-05-01) go1.25.7 linux/amd64".
+func versionString() string {
+	rev, when := commit, date
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && rev == "":
+				rev = s.Value[:min(len(s.Value), 7)]
+			case s.Key == "vcs.time" && when == "":
+				when = s.Value[:min(len(s.Value), 10)]
+			}
+		}
+	}
+	s := "peek " + buildVersion()
+	switch {
+	case rev != "" && when != "":
+		s += fmt.Sprintf(" (%s, %s)", rev, when)
+	case rev != "":
+		s += " (" + rev + ")"
+	}
+	return fmt.Sprintf("%s %s %s/%s", s, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
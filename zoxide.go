@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runZ handles `peek z QUERY... [options]`: the dir zoxide ranks best
+// for the keywords, listed with the options that follow them.
+func runZ(args []string) {
+	n := 0
+	for n < len(args) && !strings.HasPrefix(args[n], "-") {
+		n++
+	}
+	query, opts := args[:n], args[n:]
+	if len(query) == 0 {
+		if len(opts) > 0 && (opts[0] == "-h" || opts[0] == "--help") {
+			fmt.Println("Usage: peek z QUERY... [options]")
+			fmt.Println("Lists the dir zoxide's database ranks best for QUERY, as zoxide query")
+			fmt.Println("picks it, with any of peek's options; --zoxide also ranks it up.")
+			return
+		}
+		fatal(fmt.Errorf("usage: peek z QUERY... [options] (see peek -h for the options)"))
+	}
+	dir, err := zoxideQuery(query)
+	if err != nil {
+		fatal(err)
+	}
+	runList(append(opts, "--", dir))
+}
+
+// zoxideQuery asks zoxide for its best match for keywords.
+func zoxideQuery(keywords []string) (string, error) {
+	out, err := exec.Command("zoxide", append([]string{"query", "--"}, keywords...)...).Output()
+	var exit *exec.ExitError
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		return "", fmt.Errorf("peek z needs zoxide on the PATH (https://github.com/ajeetdsouza/zoxide)")
+	case errors.As(err, &exit):
+		return "", fmt.Errorf("zoxide has no match for %q", strings.Join(keywords, " "))
+	case err != nil:
+		return "", err
+	}
+	dir := strings.TrimSpace(string(out))
+	if dir == "" {
+		return "", fmt.Errorf("zoxide has no match for %q", strings.Join(keywords, " "))
+	}
+	return dir, nil
+}
+
+// zoxideAdd ranks the local dir up in zoxide's database, as cd does with
+// zoxide's hook. Without zoxide, or for dirs inside archives, it does
+// nothing.
+func zoxideAdd(dir string) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return
+	}
+	exec.Command("zoxide", "add", "--", dir).Run()
+}